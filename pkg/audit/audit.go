@@ -0,0 +1,153 @@
+// Package audit writes the append-only JSON-lines audit log of the changes conditioner makes.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Results of an audited change.
+const (
+	ResultSuccess string = "success"
+	ResultFailure string = "failure"
+)
+
+// Entry is a single line of the audit log, recording one change to a condition of a node.
+type Entry struct {
+	// Timestamp is the time of the change.
+	Timestamp time.Time `json:"timestamp"`
+	// Context is the name of the kubeconfig context the change was made with.
+	Context string `json:"context,omitempty"`
+	// Server is the address of the API server of the cluster.
+	Server string `json:"server,omitempty"`
+	// Actor is the identity of the user making the change.
+	Actor string `json:"actor,omitempty"`
+	// Node is the name of the node.
+	Node string `json:"node"`
+	// ConditionType is the type of the changed condition, or the pattern selecting the conditions
+	// if the change failed before they were selected.
+	ConditionType string `json:"type,omitempty"`
+	// Operation is the JSON Patch operation applied to the condition: add, replace or remove.
+	Operation string `json:"op,omitempty"`
+	// Justification is why the change was made, as given with --justification, e.g. to override a change freeze.
+	Justification string `json:"justification,omitempty"`
+	// Before is the condition before the change, it is nil if the condition was added.
+	Before *corev1.NodeCondition `json:"before,omitempty"`
+	// After is the condition after the change, it is nil if the condition was removed.
+	After *corev1.NodeCondition `json:"after,omitempty"`
+	// Result is ResultSuccess or ResultFailure.
+	Result string `json:"result"`
+	// Error is the error the change failed with.
+	Error string `json:"error,omitempty"`
+}
+
+// Log is an append-only audit log file rotated by size.
+type Log struct {
+	// path is the path of the audit log file.
+	path string
+
+	// maxSize is the size in bytes after which the file is rotated.
+	maxSize int64
+
+	// maxBackups is the number of rotated files kept, named path.1 (the newest) to path.<maxBackups>.
+	maxBackups int
+}
+
+// New returns the audit log at path, rotated once it would grow beyond maxSize bytes and keeping maxBackups rotated files.
+func New(path string, maxSize int64, maxBackups int) *Log {
+	return &Log{path: path, maxSize: maxSize, maxBackups: maxBackups}
+}
+
+// Check verifies the audit log can be written by opening it for appending, creating it and its directory if needed.
+// It is called before any change is made, so a change is never made that cannot be recorded.
+func (l *Log) Check() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// Write appends the entries to the audit log, one JSON object per line, rotating the file first if needed.
+func (l *Log) Write(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+
+	if err := l.rotate(int64(len(data))); err != nil {
+		return fmt.Errorf("rotating %s: %w", l.path, err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// rotate moves the audit log aside if writing size more bytes would grow it beyond maxSize.
+// An empty file is never rotated, so a single write larger than maxSize still succeeds.
+func (l *Log) rotate(size int64) error {
+	info, err := os.Stat(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Size() == 0 || info.Size()+size <= l.maxSize {
+		return nil
+	}
+
+	if l.maxBackups <= 0 {
+		return os.Remove(l.path)
+	}
+
+	if err := os.Remove(l.backup(l.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(l.path, l.backup(1))
+}
+
+// backup returns the path of the i-th rotated file.
+func (l *Log) backup(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// readEntries decodes the JSON lines of the audit log file at path.
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+
+	return entries
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	log := New(path, 1024*1024, 3)
+
+	entry := Entry{
+		Timestamp:     time.Date(2024, 9, 1, 7, 21, 47, 0, time.UTC),
+		Context:       "prod",
+		Server:        "https://prod.example.com",
+		Actor:         "jane@example.com",
+		Node:          "worker-01",
+		ConditionType: "NodeMaintenance",
+		Operation:     "replace",
+		Before:        &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionFalse},
+		After:         &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue},
+		Result:        ResultSuccess,
+	}
+
+	require.NoError(t, log.Write(entry))
+	require.NoError(t, log.Write(Entry{Node: "worker-02", ConditionType: "NodeMaintenance", Result: ResultFailure, Error: "not found"}))
+	require.NoError(t, log.Write())
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "prod", entries[0].Context)
+	assert.Equal(t, corev1.ConditionFalse, entries[0].Before.Status)
+	assert.Equal(t, corev1.ConditionTrue, entries[0].After.Status)
+	assert.Equal(t, "not found", entries[1].Error)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	require.NoError(t, New(path, 1024*1024, 3).Check())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	blocked := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocked, nil, 0o600))
+	assert.Error(t, New(filepath.Join(blocked, "audit.log"), 1024*1024, 3).Check())
+}
+
+func TestWriteRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	entry := Entry{Node: "worker-01", ConditionType: "NodeMaintenance", Result: ResultSuccess}
+
+	line, err := json.Marshal(entry)
+	require.NoError(t, err)
+
+	// Each file holds two entries before it is rotated.
+	log := New(path, int64(2*(len(line)+1)), 2)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, log.Write(entry))
+	}
+
+	assert.Len(t, readEntries(t, path), 1)
+	assert.Len(t, readEntries(t, path+".1"), 2)
+	assert.Len(t, readEntries(t, path+".2"), 2)
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestWriteOversizedEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log := New(path, 1, 1)
+
+	require.NoError(t, log.Write(Entry{Node: "worker-01", Result: ResultSuccess}))
+	assert.Len(t, readEntries(t, path), 1)
+	assert.NoFileExists(t, path+".1")
+
+	require.NoError(t, log.Write(Entry{Node: "worker-02", Result: ResultSuccess}))
+	assert.Len(t, readEntries(t, path), 1)
+	assert.Len(t, readEntries(t, path+".1"), 1)
+}
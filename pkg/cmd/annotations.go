@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/jsonpatch"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// expiryAnnotation is the node annotation recording, per condition type, when a condition set with --ttl expires.
+// Its value is a JSON object mapping condition types to RFC 3339 timestamps.
+const expiryAnnotation string = "conditioner.devbytes.cloud/expiry"
+
+// authorAnnotation is the node annotation recording, per condition type, who last changed the condition.
+// Its value is a JSON object mapping condition types to authorRecords.
+const authorAnnotation string = "conditioner.devbytes.cloud/authors"
+
+// authorRecord records who changed a condition, when, from which host and with which command line.
+type authorRecord struct {
+	// Who is the identity of the user, as determined by the whoami-source.
+	Who string `json:"who"`
+	// When is the time of the change.
+	When time.Time `json:"when"`
+	// Host is the host name of the machine the plugin ran on.
+	Host string `json:"host,omitempty"`
+	// Command is the command line of the plugin, with credentials redacted.
+	Command string `json:"command,omitempty"`
+	// Operation is the JSON Patch operation applied to the condition: add, replace or remove.
+	Operation string `json:"op"`
+}
+
+// readExpiries decodes the expiry annotation of the node.
+// It returns an empty map if the node has no expiry annotation.
+func readExpiries(node *corev1.Node) (map[string]time.Time, error) {
+	expiries := map[string]time.Time{}
+
+	value, ok := node.Annotations[expiryAnnotation]
+	if !ok {
+		return expiries, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &expiries); err != nil {
+		return nil, fmt.Errorf("decoding annotation %s: %w", expiryAnnotation, err)
+	}
+
+	return expiries, nil
+}
+
+// readAuthors decodes the author annotation of the node.
+// It returns an empty map if the node has no author annotation.
+func readAuthors(node *corev1.Node) (map[string]authorRecord, error) {
+	authors := map[string]authorRecord{}
+
+	value, ok := node.Annotations[authorAnnotation]
+	if !ok {
+		return authors, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &authors); err != nil {
+		return nil, fmt.Errorf("decoding annotation %s: %w", authorAnnotation, err)
+	}
+
+	return authors, nil
+}
+
+// updateExpiries records in annotations the new value of the expiry annotation after applying the updates.
+// A nil expiry deletes the entry for that condition type, and the annotation is removed entirely once its last
+// entry is deleted. Nothing is recorded when the annotation does not change.
+func updateExpiries(node *corev1.Node, updates map[corev1.NodeConditionType]*time.Time, annotations map[string]*string) error {
+	expiries, err := readExpiries(node)
+	if err != nil {
+		return err
+	}
+
+	updated := maps.Clone(expiries)
+	for conditionType, expiry := range updates {
+		if expiry == nil {
+			delete(updated, string(conditionType))
+			continue
+		}
+
+		updated[string(conditionType)] = expiry.UTC().Truncate(time.Second)
+	}
+
+	if maps.EqualFunc(expiries, updated, time.Time.Equal) {
+		return nil
+	}
+
+	return setJSONAnnotation(annotations, expiryAnnotation, updated)
+}
+
+// updateAuthors records in annotations the new value of the author annotation with the records of the
+// changed condition types replacing their previous records.
+func updateAuthors(node *corev1.Node, records map[corev1.NodeConditionType]authorRecord, annotations map[string]*string) error {
+	authors, err := readAuthors(node)
+	if err != nil {
+		return err
+	}
+
+	for conditionType, record := range records {
+		authors[string(conditionType)] = record
+	}
+
+	return setJSONAnnotation(annotations, authorAnnotation, authors)
+}
+
+// setJSONAnnotation records the JSON encoding of value as the new value of the annotation key,
+// or records the removal of the annotation when value is empty.
+func setJSONAnnotation[V any](annotations map[string]*string, key string, value map[string]V) error {
+	if len(value) == 0 {
+		annotations[key] = nil
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s := string(encoded)
+	annotations[key] = &s
+	return nil
+}
+
+// annotationPatches returns the JSON Patch operations setting the annotations of the node to the given values,
+// a nil value removes the annotation. If the node has no annotations yet they are added as a single object,
+// as JSON Patch cannot add members to an object that does not exist.
+func annotationPatches(node *corev1.Node, annotations map[string]*string) []interface{} {
+	var patches []interface{}
+
+	if node.Annotations == nil {
+		added := map[string]string{}
+		for key, value := range annotations {
+			if value != nil {
+				added[key] = *value
+			}
+		}
+
+		if len(added) > 0 {
+			patches = append(patches, jsonpatch.GenerateAnnotationsPatch(added))
+		}
+
+		return patches
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		value := annotations[key]
+		if value != nil {
+			patches = append(patches, jsonpatch.GenerateAnnotationPatch(node.Annotations, key, *value))
+		} else if _, ok := node.Annotations[key]; ok {
+			patches = append(patches, jsonpatch.GenerateAnnotationRemovePatch(key))
+		}
+	}
+
+	return patches
+}
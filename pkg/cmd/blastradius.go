@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// blastRadiusLimits returns the maximum number and percentage of nodes the command may change.
+// The --max-nodes and --max-nodes-percent flags override the configuration when provided, but they may only
+// tighten the limits set by the cluster policy, never raise or disable them.
+func blastRadiusLimits(cmd *cobra.Command, conf *config.Config) (int, int, error) {
+	maxNodes, maxNodesPercent := conf.MaxNodes, conf.MaxNodesPercent
+	clusterMaxNodes, clusterMaxNodesPercent := conf.ClusterLimits()
+
+	if cmd.Flags().Changed("max-nodes") {
+		var err error
+		maxNodes, err = cmd.Flags().GetInt("max-nodes")
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if clusterMaxNodes > 0 && (maxNodes == 0 || maxNodes > clusterMaxNodes) {
+			return 0, 0, fmt.Errorf("--max-nodes cannot raise or disable the limit of %d nodes set by the cluster policy", clusterMaxNodes)
+		}
+	}
+
+	if cmd.Flags().Changed("max-nodes-percent") {
+		var err error
+		maxNodesPercent, err = cmd.Flags().GetInt("max-nodes-percent")
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if clusterMaxNodesPercent > 0 && (maxNodesPercent == 0 || maxNodesPercent > clusterMaxNodesPercent) {
+			return 0, 0, fmt.Errorf("--max-nodes-percent cannot raise or disable the limit of %d%% set by the cluster policy", clusterMaxNodesPercent)
+		}
+	}
+
+	if maxNodes < 0 {
+		return 0, 0, fmt.Errorf("--max-nodes must not be negative")
+	}
+
+	if maxNodesPercent < 0 || maxNodesPercent > 100 {
+		return 0, 0, fmt.Errorf("--max-nodes-percent must be between 0 and 100")
+	}
+
+	// With several cluster policies the tightest limit applies, whichever policy was applied last.
+	return config.TighterLimit(maxNodes, clusterMaxNodes), config.TighterLimit(maxNodesPercent, clusterMaxNodesPercent), nil
+}
+
+// checkBlastRadius refuses to change count nodes when the max-nodes and max-nodes-percent limits do not allow it.
+// Unlike the confirmation prompt it cannot be answered, so it also guards unattended runs such as CI jobs.
+func (o *changeOptions) checkBlastRadius(count int) error {
+	if o.maxNodes > 0 && count > o.maxNodes {
+		return fmt.Errorf("refusing to change %d nodes, the limit is %d nodes, use --max-nodes to raise it", count, o.maxNodes)
+	}
+
+	if o.maxNodesPercent == 0 {
+		return nil
+	}
+
+	nodes, err := listNodes(o.client, nil)
+	if err != nil {
+		return fmt.Errorf("counting the nodes in the cluster: %w", err)
+	}
+
+	if count*100 > len(nodes)*o.maxNodesPercent {
+		return fmt.Errorf("refusing to change %d of the cluster's %d nodes, the limit is %d%% of the cluster, use --max-nodes-percent to raise it", count, len(nodes), o.maxNodesPercent)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBlastRadiusLimits(t *testing.T) {
+	conf := &config.Config{MaxNodes: 20, MaxNodesPercent: 50}
+
+	t.Run("Success: configuration", func(t *testing.T) {
+		maxNodes, maxNodesPercent, err := blastRadiusLimits(newCompleteCommand(t, nil), conf)
+		require.NoError(t, err)
+		assert.Equal(t, 20, maxNodes)
+		assert.Equal(t, 50, maxNodesPercent)
+	})
+
+	t.Run("Success: flags override the configuration", func(t *testing.T) {
+		maxNodes, maxNodesPercent, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes": "100", "max-nodes-percent": "0"}), conf)
+		require.NoError(t, err)
+		assert.Equal(t, 100, maxNodes)
+		assert.Equal(t, 0, maxNodesPercent)
+	})
+
+	policy, err := conf.WithClusterPolicy(config.Profile{MaxNodes: 10, MaxNodesPercent: 30})
+	require.NoError(t, err)
+
+	t.Run("Success: cluster policy limits apply", func(t *testing.T) {
+		maxNodes, maxNodesPercent, err := blastRadiusLimits(newCompleteCommand(t, nil), policy)
+		require.NoError(t, err)
+		assert.Equal(t, 10, maxNodes)
+		assert.Equal(t, 30, maxNodesPercent)
+	})
+
+	t.Run("Success: flags tighten the cluster policy limits", func(t *testing.T) {
+		maxNodes, maxNodesPercent, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes": "5", "max-nodes-percent": "10"}), policy)
+		require.NoError(t, err)
+		assert.Equal(t, 5, maxNodes)
+		assert.Equal(t, 10, maxNodesPercent)
+	})
+
+	t.Run("Error: --max-nodes raises the cluster policy limit", func(t *testing.T) {
+		_, _, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes": "11"}), policy)
+		assert.EqualError(t, err, "--max-nodes cannot raise or disable the limit of 10 nodes set by the cluster policy")
+	})
+
+	t.Run("Error: --max-nodes 0 disables the cluster policy limit", func(t *testing.T) {
+		_, _, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes": "0"}), policy)
+		assert.EqualError(t, err, "--max-nodes cannot raise or disable the limit of 10 nodes set by the cluster policy")
+	})
+
+	t.Run("Error: --max-nodes-percent 0 disables the cluster policy limit", func(t *testing.T) {
+		_, _, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes-percent": "0"}), policy)
+		assert.EqualError(t, err, "--max-nodes-percent cannot raise or disable the limit of 30% set by the cluster policy")
+	})
+
+	t.Run("Error: negative --max-nodes", func(t *testing.T) {
+		_, _, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes": "-1"}), conf)
+		assert.EqualError(t, err, "--max-nodes must not be negative")
+	})
+
+	t.Run("Error: --max-nodes-percent above 100", func(t *testing.T) {
+		_, _, err := blastRadiusLimits(newCompleteCommand(t, map[string]string{"max-nodes-percent": "150"}), conf)
+		assert.EqualError(t, err, "--max-nodes-percent must be between 0 and 100")
+	})
+}
+
+func TestCheckBlastRadius(t *testing.T) {
+	var objects []runtime.Object
+	for i := 0; i < 10; i++ {
+		objects = append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+
+	nodeNames := []string{"node-0", "node-1", "node-2", "node-3"}
+
+	tests := []struct {
+		name            string
+		maxNodes        int
+		maxNodesPercent int
+		expectedErr     string
+	}{
+		{name: "Success: no limits"},
+		{name: "Success: within the limits", maxNodes: 4, maxNodesPercent: 40},
+		{
+			name:        "Error: more than max-nodes",
+			maxNodes:    3,
+			expectedErr: "refusing to change 4 nodes, the limit is 3 nodes, use --max-nodes to raise it",
+		},
+		{
+			name:            "Error: more than max-nodes-percent",
+			maxNodesPercent: 30,
+			expectedErr:     "refusing to change 4 of the cluster's 10 nodes, the limit is 30% of the cluster, use --max-nodes-percent to raise it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &changeOptions{
+				client:          fake.NewClientset(objects...),
+				maxNodes:        tt.maxNodes,
+				maxNodesPercent: tt.maxNodesPercent,
+			}
+
+			err := o.checkBlastRadius(len(nodeNames))
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/audit"
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/devbytes-cloud/conditioner/pkg/notify"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+// changeOptions holds the options shared by the commands that change conditions: the conditioner command, prune and
+// reap. Every change they make is recorded the same way, in the author annotation, as an Event, in the audit log and
+// to the webhooks of the configuration.
+type changeOptions struct {
+	// client is used to interact with the Kubernetes API.
+	client kubernetes.Interface
+
+	// configFlags holds the configuration flags for the command.
+	configFlags *genericclioptions.ConfigFlags
+
+	// IOStreams provides the standard names for iostreams. This is useful for embedding and for unit testing.
+	genericiooptions.IOStreams
+
+	// config is the conditioner configuration the command was completed with.
+	config *config.Config
+
+	// author records who makes the changes in the author annotation. It is nil unless record-author is enabled.
+	author *authorRecord
+
+	// events indicates whether an Event is created for every condition change.
+	events bool
+
+	// actor is the identity of the user making the changes, as reported in Events and the audit log.
+	actor string
+
+	// auditLog records every change in the local audit log. It is nil unless audit-log is set.
+	auditLog *audit.Log
+
+	// notifier posts the changes to the webhooks of the configuration. It is nil when no webhooks are configured.
+	notifier *notify.Notifier
+
+	// auditEntries are the audit log entries of the changes made to the current node.
+	// They are written to the audit log once the node has been changed.
+	auditEntries []audit.Entry
+
+	// changes are the successful changes of the run, posted to the webhooks once every node has been changed.
+	changes []notify.Change
+
+	// kubeContext is the name of the kubeconfig context, as recorded in the audit log.
+	kubeContext string
+
+	// server is the address of the API server, as recorded in the audit log.
+	server string
+
+	// overrideFreeze allows conditions to be changed while a change freeze covers them.
+	overrideFreeze bool
+
+	// justification explains why the change is made, it is required to override a change freeze
+	// and recorded in the audit log.
+	justification string
+
+	// maxNodes is the maximum number of nodes the command may change, zero disables the limit.
+	maxNodes int
+
+	// maxNodesPercent is the maximum percentage of the cluster's nodes the command may change, zero disables the limit.
+	maxNodesPercent int
+
+	// yes skips the confirmation prompt.
+	yes bool
+
+	// openTerminal opens the terminal the confirmation is read from, it is replaced in tests.
+	openTerminal func() (io.ReadCloser, error)
+
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// newChangeOptions returns the changeOptions of a command writing to streams.
+func newChangeOptions(streams genericiooptions.IOStreams) changeOptions {
+	return changeOptions{
+		configFlags:  genericclioptions.NewConfigFlags(true),
+		IOStreams:    streams,
+		openTerminal: openTerminal,
+		now:          time.Now,
+	}
+}
+
+// addChangeFlags adds the flags shared by the commands that change conditions to cmd.
+func addChangeFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("max-nodes", "", 0, "Maximum number of nodes the command may change, overrides max-nodes from the configuration but may only lower the limit of the cluster policy (0 disables the limit)")
+	cmd.Flags().IntP("max-nodes-percent", "", 0, "Maximum percentage of the cluster's nodes the command may change, overrides max-nodes-percent from the configuration but may only lower the limit of the cluster policy (0 disables the limit)")
+	cmd.Flags().BoolP("override-freeze", "", false, "Change conditions during a change freeze, requires --justification and an audit-log")
+	cmd.Flags().StringP("justification", "", "", "Why the change is made, required with --override-freeze and recorded in the audit log")
+	cmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt shown when removing conditions or changing many nodes")
+}
+
+// completeChange creates the Kubernetes client unless one was already provided, applies the profile of the kubeconfig
+// context and the cluster policy to the configuration, reads the blast radius limits and sets up the recording of
+// the changes. The audit log is checked to be writable, so no node is changed that cannot be audited.
+// The change freezes are checked by the commands once they know the condition types they change, see checkFreeze.
+func (o *changeOptions) completeChange(cmd *cobra.Command, conf *config.Config) error {
+	restConfig, err := o.configFlags.ToRawKubeConfigLoader().ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.client == nil {
+		o.client, err = kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	conf, err = profileConfig(o.configFlags, conf)
+	if err != nil {
+		return err
+	}
+
+	o.config, err = clusterConfig(o.client, conf, o.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	o.maxNodes, o.maxNodesPercent, err = blastRadiusLimits(cmd, o.config)
+	if err != nil {
+		return err
+	}
+
+	o.yes, err = cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+
+	o.overrideFreeze, err = cmd.Flags().GetBool("override-freeze")
+	if err != nil {
+		return err
+	}
+
+	o.justification, err = cmd.Flags().GetString("justification")
+	if err != nil {
+		return err
+	}
+
+	o.justification = strings.TrimSpace(o.justification)
+	if o.overrideFreeze && o.justification == "" {
+		return fmt.Errorf("--override-freeze requires a --justification")
+	}
+
+	if o.config.RecordAuthor {
+		o.author, err = newAuthorRecord(o.client, o.config)
+		if err != nil {
+			return err
+		}
+	}
+
+	auditLogPath, err := o.config.AuditLogPath()
+	if err != nil {
+		return err
+	}
+
+	if auditLogPath != "" {
+		maxSize, maxBackups := o.config.AuditLogRotation()
+		o.auditLog = audit.New(auditLogPath, maxSize, maxBackups)
+
+		if err := o.auditLog.Check(); err != nil {
+			return fmt.Errorf("audit log: %w", err)
+		}
+	}
+
+	if len(o.config.Webhooks) > 0 {
+		if err := o.config.ValidateWebhooks(); err != nil {
+			return fmt.Errorf("invalid webhooks:\n%w", err)
+		}
+
+		o.notifier = notify.New(o.config.Webhooks)
+	}
+
+	o.events = o.config.Events
+
+	// Failing to create an Event only warns when a condition is changed, so a missing permission only warns as well.
+	if o.events {
+		missing, err := missingPermissions(o.client, createEvents)
+		if err != nil {
+			return err
+		}
+
+		if len(missing) > 0 {
+			fmt.Fprintf(o.ErrOut, "warning: changes are not recorded as Events, you are missing the permission to %s\n", missing[0])
+			o.events = false
+		}
+	}
+
+	// Changes are attributed to the user and cluster in Events, the audit log and notifications.
+	if o.events || o.auditLog != nil || o.notifier != nil {
+		o.server = restConfig.Host
+
+		o.kubeContext, _, err = kubeContext(o.configFlags)
+		if err != nil {
+			return err
+		}
+
+		if o.author != nil {
+			o.actor = o.author.Who
+		} else {
+			id, err := whoAmI(o.client, o.config)
+			if err != nil {
+				return err
+			}
+
+			o.actor = id.Username
+		}
+	}
+
+	return nil
+}
+
+// preflight checks the user is authorized to read the nodes with read and to patch their status before any node is
+// touched. The percentage limit is computed from the number of nodes in the cluster, so listing them is checked too
+// when it is set.
+func (o *changeOptions) preflight(read permission) error {
+	permissions := []permission{read, patchNodeStatus}
+	if o.maxNodesPercent > 0 {
+		permissions = append(permissions, listNodesAccess)
+	}
+
+	return preflight(o.client, permissions...)
+}
+
+// changeNodes changes the nodes, or every node when nodeNames is empty, with change. changedTypes returns the
+// condition types a node would change: only the nodes with changes count against the blast radius limits and are
+// confirmed to action, always when always is set, and only their condition types are checked against the change
+// freezes. A node changedTypes fails on is kept, so its error is reported when it is changed.
+func (o *changeOptions) changeNodes(nodeNames []string, action string, always bool, conditionType string, changedTypes func(node *corev1.Node) ([]string, error), change func(nodeName string) error) error {
+	nodes, err := listNodes(o.client, nodeNames)
+	if err != nil {
+		return err
+	}
+
+	var selected, conditionTypes []string
+	for i := range nodes {
+		types, err := changedTypes(&nodes[i])
+		if err != nil || len(types) > 0 {
+			selected = append(selected, nodes[i].Name)
+		}
+
+		conditionTypes = append(conditionTypes, types...)
+	}
+
+	if len(selected) == 0 {
+		return nil
+	}
+
+	slices.Sort(conditionTypes)
+	if err := o.checkFreeze(slices.Compact(conditionTypes)...); err != nil {
+		return err
+	}
+
+	if err := o.checkBlastRadius(len(selected)); err != nil {
+		return err
+	}
+
+	if err := o.confirm(action, selected, always); err != nil {
+		return err
+	}
+
+	return o.runNodes(selected, conditionType, change)
+}
+
+// runNodes changes every node with change. The audit log entries of a node are written as soon as the node has
+// been changed, a node that failed before any condition was changed is recorded as a failed change of conditionType.
+// When the audit log cannot be written no further node is changed. The successful changes are posted to the
+// webhooks once every node has been changed.
+func (o *changeOptions) runNodes(nodeNames []string, conditionType string, change func(nodeName string) error) error {
+	var errs []error
+
+	for _, nodeName := range nodeNames {
+		o.auditEntries = nil
+
+		if err := change(nodeName); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", nodeName, err))
+
+			// The node failed before any condition was patched, record the attempt itself.
+			if len(o.auditEntries) == 0 {
+				o.recordAudit(nodeName, conditionType, "", nil, nil, err)
+			}
+		}
+
+		if err := o.writeAudit(); err != nil {
+			errs = append(errs, fmt.Errorf("writing audit log, no further nodes are changed: %w", err))
+			break
+		}
+	}
+
+	o.notify()
+
+	return errors.Join(errs...)
+}
+
+// recordAudit records the change of a condition on the node for the audit log and webhooks,
+// err is the error the change failed with.
+func (o *changeOptions) recordAudit(nodeName, conditionType, operation string, before, after *corev1.NodeCondition, err error) {
+	entry := audit.Entry{
+		Timestamp:     o.now().UTC(),
+		Context:       o.kubeContext,
+		Server:        o.server,
+		Actor:         o.actor,
+		Node:          nodeName,
+		ConditionType: conditionType,
+		Operation:     operation,
+		Justification: o.justification,
+		Before:        before,
+		After:         after,
+		Result:        audit.ResultSuccess,
+	}
+
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Error = err.Error()
+	}
+
+	o.auditEntries = append(o.auditEntries, entry)
+}
+
+// writeAudit writes the audit log entries of the current node to the audit log, and keeps its successful changes
+// to be posted to the webhooks.
+func (o *changeOptions) writeAudit() error {
+	o.changes = append(o.changes, notifications(o.auditEntries)...)
+
+	if o.auditLog == nil {
+		return nil
+	}
+
+	return o.auditLog.Write(o.auditEntries...)
+}
+
+// notify posts the successful changes of the run to the webhooks. The changes have already been applied,
+// so failing to notify a webhook is reported as a warning.
+func (o *changeOptions) notify() {
+	if o.notifier == nil || len(o.changes) == 0 {
+		return
+	}
+
+	if err := o.notifier.Notify(context.Background(), o.changes); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to notify webhooks: %v\n", err)
+	}
+}
+
+// notifications returns the webhook notifications of the successful changes among the audit log entries.
+func notifications(entries []audit.Entry) []notify.Change {
+	var changes []notify.Change
+	for _, entry := range entries {
+		if entry.Result != audit.ResultSuccess || entry.Operation == "" {
+			continue
+		}
+
+		change := notify.Change{
+			Time:          entry.Timestamp,
+			Context:       entry.Context,
+			Actor:         entry.Actor,
+			Node:          entry.Node,
+			ConditionType: entry.ConditionType,
+			Operation:     entry.Operation,
+		}
+
+		if entry.Before != nil {
+			change.From = string(entry.Before.Status)
+		}
+
+		if entry.After != nil {
+			change.To = string(entry.After.Status)
+			change.Reason = entry.After.Reason
+			change.Message = entry.After.Message
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// recordAuthor records in annotations the new value of the author annotation, attributing the operations
+// applied to the condition types to the author. Nothing is recorded unless record-author is enabled.
+func (o *changeOptions) recordAuthor(node *corev1.Node, operations map[corev1.NodeConditionType]string, annotations map[string]*string) error {
+	if o.author == nil {
+		return nil
+	}
+
+	now := o.now().UTC().Truncate(time.Second)
+	records := make(map[corev1.NodeConditionType]authorRecord, len(operations))
+	for conditionType, operation := range operations {
+		record := *o.author
+		record.When = now
+		record.Operation = operation
+		records[conditionType] = record
+	}
+
+	return updateAuthors(node, records, annotations)
+}
+
+// emitEvent creates an Event for the change of a condition when events are enabled. The change has already
+// been applied, so failing to create the Event is reported as a warning rather than an error.
+func (o *changeOptions) emitEvent(node *corev1.Node, old, updated *corev1.NodeCondition) {
+	if !o.events {
+		return
+	}
+
+	if err := emitConditionEvent(o.client, node, old, updated, o.actor); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to create event for node %s: %v\n", node.Name, err)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterConfig returns the configuration with the cluster policies applied, read from the well-known
+// DefaultClusterPolicy ConfigMap and the ConfigMap named by the cluster-policy setting. The cluster policy is optional:
+// a ConfigMap that does not exist is skipped, so a cluster can opt in by creating it, and a ConfigMap the user may not
+// read is skipped with a warning written to errOut. Any other error, such as an invalid policy, fails the command.
+func clusterConfig(client kubernetes.Interface, conf *config.Config, errOut io.Writer) (*config.Config, error) {
+	for _, ref := range conf.ClusterPolicyRefs() {
+		namespace, name, err := config.SplitClusterPolicyRef(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		conf, err = applyClusterPolicy(client, conf, namespace, name, errOut)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return conf, nil
+}
+
+// applyClusterPolicy returns the configuration with the cluster policy of the ConfigMap applied, or the
+// configuration unchanged if the ConfigMap does not exist or the user may not read it.
+func applyClusterPolicy(client kubernetes.Interface, conf *config.Config, namespace, name string, errOut io.Writer) (*config.Config, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return conf, nil
+	} else if apierrors.IsForbidden(err) {
+		fmt.Fprintf(errOut, "warning: cluster policy %s/%s is not applied, you are missing the permission to get configmaps in namespace %s\n", namespace, name, namespace)
+		return conf, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading cluster policy %s/%s: %w", namespace, name, err)
+	}
+
+	data, ok := configMap.Data[config.ClusterPolicyKey]
+	if !ok {
+		return nil, fmt.Errorf("cluster policy %s/%s has no %s key", namespace, name, config.ClusterPolicyKey)
+	}
+
+	policy, err := config.ParseClusterPolicy([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("cluster policy %s/%s: %w", namespace, name, err)
+	}
+
+	applied, err := conf.WithClusterPolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("cluster policy %s/%s: %w", namespace, name, err)
+	}
+
+	return applied, nil
+}
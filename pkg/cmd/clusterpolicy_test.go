@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestClusterConfig(t *testing.T) {
+	policyConfigMap := func(namespace, name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       data,
+		}
+	}
+
+	local := &config.Config{AllowList: []string{}}
+
+	t.Run("Success: cluster policy is applied", func(t *testing.T) {
+		client := fake.NewClientset(policyConfigMap("kube-system", "conditioner-policy", map[string]string{"policy.yaml": "allow-list: [NodeMaintenance]\n"}))
+
+		got, err := clusterConfig(client, local, io.Discard)
+		require.NoError(t, err)
+		assert.EqualError(t, got.CheckType("UnderInvestigation"), "condition UnderInvestigation is not in allow-list [NodeMaintenance]")
+	})
+
+	t.Run("Success: missing ConfigMap", func(t *testing.T) {
+		got, err := clusterConfig(fake.NewClientset(), local, io.Discard)
+		require.NoError(t, err)
+		assert.Same(t, local, got)
+	})
+
+	t.Run("Success: local configuration cannot skip the default cluster policy", func(t *testing.T) {
+		conf := &config.Config{ClusterPolicy: "platform/node-policy"}
+		client := fake.NewClientset(policyConfigMap("kube-system", "conditioner-policy", map[string]string{"policy.yaml": "allow-list: [NodeMaintenance]\n"}))
+
+		got, err := clusterConfig(client, conf, io.Discard)
+		require.NoError(t, err)
+		assert.EqualError(t, got.CheckType("UnderInvestigation"), "condition UnderInvestigation is not in allow-list [NodeMaintenance]")
+	})
+
+	t.Run("Success: default cluster policy takes precedence over the configured one", func(t *testing.T) {
+		conf := &config.Config{ClusterPolicy: "platform/node-policy"}
+		client := fake.NewClientset(
+			policyConfigMap("platform", "node-policy", map[string]string{"policy.yaml": "allow-list: [UnderInvestigation]\ndeny-list: [Legacy]\n"}),
+			policyConfigMap("kube-system", "conditioner-policy", map[string]string{"policy.yaml": "allow-list: [NodeMaintenance]\n"}),
+		)
+
+		got, err := clusterConfig(client, conf, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"NodeMaintenance"}, got.AllowList)
+		assert.Equal(t, []string{"Legacy"}, got.DenyList)
+	})
+
+	t.Run("Success: forbidden ConfigMap is skipped with a warning", func(t *testing.T) {
+		client := fake.NewClientset()
+		client.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("configmaps"), "conditioner-policy", errors.New("access denied"))
+		})
+
+		errOut := &bytes.Buffer{}
+		got, err := clusterConfig(client, local, errOut)
+		require.NoError(t, err)
+		assert.Same(t, local, got)
+		assert.Equal(t, "warning: cluster policy kube-system/conditioner-policy is not applied, you are missing the permission to get configmaps in namespace kube-system\n", errOut.String())
+	})
+
+	t.Run("Error: reading the cluster policy fails", func(t *testing.T) {
+		client := fake.NewClientset()
+		client.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewInternalError(errors.New("etcd unavailable"))
+		})
+
+		_, err := clusterConfig(client, local, io.Discard)
+		assert.ErrorContains(t, err, "reading cluster policy kube-system/conditioner-policy: ")
+	})
+
+	t.Run("Error: missing key", func(t *testing.T) {
+		_, err := clusterConfig(fake.NewClientset(policyConfigMap("kube-system", "conditioner-policy", nil)), local, io.Discard)
+		assert.EqualError(t, err, "cluster policy kube-system/conditioner-policy has no policy.yaml key")
+	})
+
+	t.Run("Error: unknown field", func(t *testing.T) {
+		client := fake.NewClientset(policyConfigMap("kube-system", "conditioner-policy", map[string]string{"policy.yaml": "allowlist: [NodeMaintenance]\n"}))
+
+		_, err := clusterConfig(client, local, io.Discard)
+		assert.ErrorContains(t, err, `cluster policy kube-system/conditioner-policy: error unmarshaling JSON: while decoding JSON: json: unknown field "allowlist"`)
+	})
+}
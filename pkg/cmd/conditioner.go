@@ -2,62 +2,80 @@ package cmd
 
 import (
 	"bufio"
-	"context"
-	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/user"
+	"slices"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 
 	"github.com/devbytes-cloud/conditioner/pkg/config"
 	"github.com/devbytes-cloud/conditioner/pkg/jsonpatch"
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/json"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
-	"k8s.io/client-go/kubernetes"
 )
 
 var (
 	example = `
 # Add a new condition to a node
-kubectl conditioner my-node --type Ready --status true --reason KubeletReady --message "kubelet is posting ready status"
+kubectl conditioner my-node --type NodeMaintenance --status true --reason MaintenanceScheduled --message "node is scheduled for maintenance"
 
 # Update an existing condition on a node
-kubectl conditioner my-node --type DiskPressure --status false --reason KubeletHasNoDiskPressure --message "kubelet has sufficient disk space available"
+kubectl conditioner my-node --type NodeMaintenance --status false --reason MaintenanceComplete --message "maintenance has completed"
+
+# Update only the message of an existing condition, keeping its status
+kubectl conditioner my-node --type NodeMaintenance --status keep --message "maintenance has been postponed"
 
 # Remove a condition from a node
-kubectl conditioner my-node --type NetworkUnavailable --remove
+kubectl conditioner my-node --type NodeMaintenance --remove
+
+# Remove every condition whose type matches a glob (or a /regular expression/)
+kubectl conditioner my-node --type 'team-a.example.com/*' --remove
+
+# Remove every condition that is not one of the kubelet's built-in conditions
+kubectl conditioner my-node --remove-all-custom
+
+# Set a condition as a team, prefixing the type with the team's prefix (e.g. storage.example.com/Healthy)
+kubectl conditioner my-node --team storage --type Healthy --status true --reason VolumesAttached
+
+# Set a temporary condition that 'kubectl conditioner prune' removes once it has expired
+kubectl conditioner my-node --type UnderInvestigation --status true --reason PagerAlert --ttl 4h
+
+# Condition a node named after a subcommand, such as a node called 'config'
+kubectl conditioner node/config --type NodeMaintenance --status true --reason MaintenanceScheduled
 
 # Apply a condition to all nodes by piping kubectl output directly
-kubectl get nodes -o name | kubectl conditioner --type Ready --status true --reason KubeletReady --message "kubelet is posting ready status"
+kubectl get nodes -o name | kubectl conditioner --type NodeMaintenance --status true --reason MaintenanceScheduled --message "node is scheduled for maintenance"
 `
 
 	long = `The 'conditioner' command allows you to add, update, or remove status conditions on nodes. 
 You need to provide one or more node names as arguments and use flags to specify the details of the condition. 
-The '--type' flag is required and it specifies the type of condition you wish to interact with. 
-The '--status' flag sets the status for the specific status condition and it can be 'true', 'false', or left blank for 'unknown'. 
+The '--type' flag specifies the type of condition you wish to interact with. 
+The '--status' flag sets the status for the specific status condition and it can be 'true', 'false', 'unknown' (the default when left blank), 
+or 'keep' to keep the current status of an existing condition while updating its reason or message. 
 The '--reason' flag sets the reason for the specific status condition. 
 The '--message' flag sets the message for the specific status condition. 
-If you wish to remove the condition from the node entirely, use the '--remove' flag.`
+If you wish to remove the condition from the node entirely, use the '--remove' flag. 
+When removing, '--type' may be a glob (e.g. 'team-a.example.com/*') or a regular expression wrapped in slashes. 
+The '--remove-all-custom' flag removes every condition that is not one of the kubelet's built-in conditions. 
+The '--ttl' flag records when the condition expires, expired conditions are removed by 'kubectl conditioner prune'. 
+A node named after a subcommand (prune, reap, config, history, help or completion) runs that subcommand instead, 
+give it as 'node/<name>' (e.g. 'node/config') to condition the node.`
 )
 
+// keepStatus is the --status value that keeps the status of an existing condition.
+const keepStatus string = "keep"
+
 // ConditionOptions is a struct that holds the configuration for the condition command.
 type ConditionOptions struct {
-	// client is a pointer to a Clientset object that is used to interact with the Kubernetes API.
-	client *kubernetes.Clientset
-
-	// configFlags holds the configuration flags for the command.
-	configFlags *genericclioptions.ConfigFlags
-
-	// IOStreams provides the standard names for iostreams. This is useful for embedding and for unit testing.
-	genericiooptions.IOStreams
+	// changeOptions holds the client, the configuration and the recording of the changes shared with prune and reap.
+	changeOptions
 
 	// nodeNames are the names of the nodes that the command is being run against.
 	nodeNames []string
@@ -65,9 +83,31 @@ type ConditionOptions struct {
 	// remove is a boolean that indicates whether the condition should be removed.
 	remove bool
 
+	// removeAllCustom indicates whether every condition outside of config.DefaultProtectedTypes, the kubelet's
+	// built-in conditions, should be removed.
+	removeAllCustom bool
+
+	// typePattern is the glob or regular expression selecting the condition types to remove.
+	// It is empty when a literal condition type was provided.
+	typePattern string
+
 	// condition is a pointer to a NodeCondition object that represents the condition to be added or updated.
 	condition *corev1.NodeCondition
 
+	// ttl is how long an added or updated condition is valid for before `prune` removes it.
+	// A zero ttl means the condition never expires.
+	ttl time.Duration
+
+	// team is the team setting the condition, it restricts the condition types to the team's prefix.
+	team string
+
+	// forceProtected allows protected condition types to be modified or removed.
+	forceProtected bool
+
+	// nodeSelector restricts the nodes the condition may be set on, as required by the policy of its type.
+	// It is nil when the nodes are not restricted.
+	nodeSelector labels.Selector
+
 	// args is a slice of strings that contains the arguments that were passed to the command.
 	args []string
 }
@@ -75,8 +115,7 @@ type ConditionOptions struct {
 // NewConditionOptions is a function that creates a new ConditionOptions.
 func NewConditionOptions(streams genericiooptions.IOStreams) *ConditionOptions {
 	return &ConditionOptions{
-		configFlags: genericclioptions.NewConfigFlags(true),
-		IOStreams:   streams,
+		changeOptions: newChangeOptions(streams),
 	}
 }
 
@@ -92,6 +131,7 @@ func NewCmdCondition(streams genericiooptions.IOStreams) *cobra.Command {
 		Long:         long,
 		Example:      example,
 		SilenceUsage: true,
+		Args:         cobra.ArbitraryArgs,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			o.args = args
 
@@ -107,8 +147,7 @@ func NewCmdCondition(streams genericiooptions.IOStreams) *cobra.Command {
 			return o.setNodeNames(merged)
 		},
 		RunE: func(c *cobra.Command, args []string) error {
-			fs := config.FS{}
-			conf, err := config.Read(fs)
+			conf, err := loadConfig(c)
 			if err != nil {
 				return err
 			}
@@ -117,6 +156,14 @@ func NewCmdCondition(streams genericiooptions.IOStreams) *cobra.Command {
 				return err
 			}
 
+			if err := o.checkBlastRadius(len(o.nodeNames)); err != nil {
+				return err
+			}
+
+			if err := o.confirm(o.describe(), o.nodeNames, o.remove); err != nil {
+				return err
+			}
+
 			if err := o.Run(); err != nil {
 				return err
 			}
@@ -125,21 +172,43 @@ func NewCmdCondition(streams genericiooptions.IOStreams) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("status", "", "", "Status for the specific status condition [true, false]")
+	cmd.Flags().StringP("status", "", "", "Status for the specific status condition [true, false, unknown, keep]")
 	cmd.Flags().StringP("reason", "r", "", "Reason for the specific status condition")
 	cmd.Flags().StringP("message", "", "", "Message for the specific status condition")
-	cmd.Flags().StringP("type", "", "", "(required): type of condition you wish to interact with")
+	cmd.Flags().StringP("type", "", "", "(required unless --remove-all-custom): type of condition you wish to interact with, may be a glob or /regex/ when removing")
 	cmd.Flags().BoolP("remove", "x", false, "If you wish to remove the condition from the node entirely")
+	cmd.Flags().BoolP("remove-all-custom", "", false, "Remove every condition that is not one of the kubelet's built-in conditions")
+	cmd.Flags().BoolP("force-protected", "", false, "Allow protected condition types, such as the kubelet's built-in conditions, to be modified or removed")
+	cmd.Flags().StringP("team", "", "", "Team setting the condition, unprefixed condition types are prefixed with the team's prefix")
+	cmd.Flags().DurationP("ttl", "", 0, "How long the condition is valid for before 'kubectl conditioner prune' expires it (e.g. 4h)")
+	addChangeFlags(cmd)
 
-	if err := cmd.MarkFlagRequired("type"); err != nil {
-		panic(fmt.Sprintf("failed to mark %s flag required: %s", "message", err.Error()))
-	}
+	cmd.MarkFlagsOneRequired("type", "remove-all-custom")
+	cmd.MarkFlagsMutuallyExclusive("type", "remove-all-custom")
+
+	cmd.PersistentFlags().StringP("config", "", "", fmt.Sprintf("Path to the conditioner configuration file, overrides $%s", config.EnvConfig))
 
 	o.configFlags.AddFlags(cmd.Flags())
 
+	cmd.AddCommand(NewCmdPrune(streams))
+	cmd.AddCommand(NewCmdReap(streams))
+	cmd.AddCommand(NewCmdConfig(streams))
+	cmd.AddCommand(NewCmdHistory(streams))
+
 	return cmd
 }
 
+// loadConfig reads the configuration file selected by the --config flag, the CONDITIONER_CONFIG
+// environment variable or the default locations, falling back to the built-in defaults.
+func loadConfig(c *cobra.Command) (*config.Config, error) {
+	override, err := c.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Load(config.FS{}, override)
+}
+
 // setNodeNames validates and normalizes the provided node name arguments, storing the
 // results in o.nodeNames. It returns an error if no names are supplied or if any
 // name is empty after normalization.
@@ -162,140 +231,387 @@ func (o *ConditionOptions) setNodeNames(args []string) error {
 }
 
 // Complete sets all information required for updating the current context
-// It retrieves the restConfig from the configFlags and creates a new Kubernetes client.
-// It also sets the condition status, reason, message, type, and remove flag from the command flags.
+// It creates the Kubernetes client and sets up the recording of the changes with completeChange.
+// It also sets the condition status, reason, message, type, and remove flag from the command flags,
+// and checks the user is authorized to condition nodes before any node is touched.
 func (o *ConditionOptions) Complete(cmd *cobra.Command, _ []string, config *config.Config) error {
-	// Get the restConfig from the configFlags
-	restConfig, err := o.configFlags.ToRawKubeConfigLoader().ClientConfig()
+	if err := o.completeChange(cmd, config); err != nil {
+		return err
+	}
+
+	config = o.config
+	o.condition = &corev1.NodeCondition{}
+
+	status, err := cmd.Flags().GetString("status")
 	if err != nil {
 		return err
 	}
 
-	// Create a new Kubernetes client
-	o.client, err = kubernetes.NewForConfig(restConfig)
+	o.condition.Status, err = parseConditionStatus(status)
 	if err != nil {
 		return err
 	}
 
-	o.condition = &corev1.NodeCondition{}
+	o.condition.Reason, err = cmd.Flags().GetString("reason")
+	if err != nil {
+		return err
+	}
 
-	status, err := cmd.Flags().GetString("status")
+	o.condition.Message, err = cmd.Flags().GetString("message")
 	if err != nil {
 		return err
 	}
 
-	switch status {
-	case "true":
-		o.condition.Status = corev1.ConditionTrue
-	case "false":
-		o.condition.Status = corev1.ConditionFalse
-	default:
-		o.condition.Status = corev1.ConditionUnknown
+	if err := o.preflight(getNodes); err != nil {
+		return err
+	}
+
+	// Get the type from the command flags and set the condition type
+	conditionType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return err
+	}
 
+	o.remove, err = cmd.Flags().GetBool("remove")
+	if err != nil {
+		return err
 	}
 
-	o.condition.Reason, err = cmd.Flags().GetString("reason")
+	o.removeAllCustom, err = cmd.Flags().GetBool("remove-all-custom")
 	if err != nil {
 		return err
 	}
 
-	o.condition.Message, err = cmd.Flags().GetString("message")
+	o.forceProtected, err = cmd.Flags().GetBool("force-protected")
+	if err != nil {
+		return err
+	}
+
+	o.ttl, err = cmd.Flags().GetDuration("ttl")
+	if err != nil {
+		return err
+	}
+
+	if o.ttl < 0 {
+		return fmt.Errorf("--ttl must not be negative")
+	}
+
+	if o.ttl != 0 && (o.remove || o.removeAllCustom) {
+		return fmt.Errorf("--ttl cannot be used when removing conditions")
+	}
+
+	o.team, err = cmd.Flags().GetString("team")
 	if err != nil {
 		return err
 	}
 
-	if config.WhoAmI {
-		u, err := user.Current()
+	if o.team == "" {
+		o.team = config.DefaultTeam
+	}
+
+	if _, ok := config.Teams[o.team]; o.team != "" && !ok {
+		return fmt.Errorf("unknown team %s", o.team)
+	}
+
+	if err := o.completeType(conditionType, config); err != nil {
+		return err
+	}
+
+	// The identity is prepended once the message the user wrote has been validated against the policy.
+	if config.WhoAmI && !o.remove {
+		o.condition.Message, err = whoAmIMessage(o.client, config, o.condition.Message)
 		if err != nil {
 			return err
 		}
 
-		o.condition.Message = fmt.Sprintf("%s: %s", u.Username, o.condition.Message)
+		if limit := config.MessageLimit(); len(o.condition.Message) > limit {
+			return fmt.Errorf("message is %d characters long including the identity, it must be no more than %d characters", len(o.condition.Message), limit)
+		}
 	}
 
-	// Get the type from the command flags and set the condition type
-	conditionType, err := cmd.Flags().GetString("type")
+	// Changes that are not limited to a single condition type are covered by every freeze.
+	frozenType := string(o.condition.Type)
+	if o.removeAllCustom || o.typePattern != "" {
+		frozenType = ""
+	}
+
+	return o.checkFreeze(frozenType)
+}
+
+// completeType qualifies and validates the condition type, or the pattern selecting the conditions to remove,
+// and validates the condition against the configuration.
+func (o *ConditionOptions) completeType(conditionType string, config *config.Config) error {
+	var err error
+
+	if o.removeAllCustom {
+		o.remove = true
+		return nil
+	}
+
+	if conditionType == "" {
+		return fmt.Errorf("either --type or --remove-all-custom must be provided")
+	}
+
+	conditionType, err = config.QualifyType(o.team, conditionType)
 	if err != nil {
 		return err
 	}
 
-	if len(config.AllowList) != 0 {
-		if ok := allowedType(conditionType, config.AllowList); !ok {
-			return fmt.Errorf("condition %s is not in allow-list %v", conditionType, config.AllowList)
+	if pattern.IsPattern(conditionType) {
+		if !o.remove {
+			return fmt.Errorf("condition type pattern %s can only be used with --remove", conditionType)
 		}
+
+		if err := pattern.Validate(conditionType); err != nil {
+			return err
+		}
+
+		o.typePattern = conditionType
+		return nil
+	}
+
+	if err := config.CheckTeam(o.team, conditionType); err != nil {
+		return err
+	}
+
+	if config.ClusterProtected(conditionType) {
+		return fmt.Errorf("condition %s is protected by the cluster policy, it cannot be modified even with --force-protected", conditionType)
+	}
+
+	if config.Protected(conditionType) && !o.forceProtected {
+		return fmt.Errorf("condition %s is protected as it is owned by the kubelet or node controller, use --force-protected to modify it anyway", conditionType)
+	}
+
+	if err := config.CheckType(conditionType); err != nil {
+		return err
 	}
 
 	o.condition.Type = corev1.NodeConditionType(conditionType)
 
-	o.remove, err = cmd.Flags().GetBool("remove")
-	if err != nil {
+	if o.remove {
+		return validateRemove(conditionType, config)
+	}
+
+	if err := validateCondition(o.condition, config); err != nil {
 		return err
 	}
 
-	return nil
+	o.nodeSelector, err = nodeSelector(conditionType, config)
+	return err
 }
 
 // Run handles the condition applying or removal on nodes.
+// Every change, and every node that could not be changed, is recorded in the audit log when it is enabled.
 func (o *ConditionOptions) Run() error {
-	var errs []error
-
-	for _, nodeName := range o.nodeNames {
-		if err := o.runForNode(nodeName); err != nil {
-			errs = append(errs, fmt.Errorf("%s: %w", nodeName, err))
-		}
-	}
-
-	if len(errs) > 0 {
-		return errors.Join(errs...)
+	conditionType := string(o.condition.Type)
+	if o.typePattern != "" {
+		conditionType = o.typePattern
 	}
 
-	return nil
+	return o.runNodes(o.nodeNames, conditionType, o.runForNode)
 }
 
 // runForNode applies or removes the configured condition on a single node. It fetches
 // the node from the Kubernetes API, generates the appropriate JSON Patch operation,
 // applies it to the node's status, and prints a confirmation message.
 func (o *ConditionOptions) runForNode(nodeName string) error {
-	node, err := o.client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	if o.removeAllCustom || o.typePattern != "" {
+		return o.removeMatching(nodeName)
+	}
+
+	var oldConditions *corev1.NodeCondition
+	var patch jsonpatch.JsonPatch
+	node, err := patchStatus(o.client, nodeName, func(node *corev1.Node) ([]interface{}, error) {
+		if o.nodeSelector != nil && !o.nodeSelector.Matches(labels.Set(node.Labels)) {
+			return nil, fmt.Errorf("condition %s may only be set on nodes matching %q", o.condition.Type, o.nodeSelector.String())
+		}
+
+		var index int
+		oldConditions, index = findConditionType(node.Status.Conditions, o.condition.Type)
+
+		if index == -1 && o.remove {
+			return nil, fmt.Errorf("condition type of %s does not exist", o.condition.Type)
+		}
+
+		if index == -1 && o.condition.Status == "" {
+			return nil, fmt.Errorf("condition type of %s does not exist, --status %s can only be used to update an existing condition", o.condition.Type, keepStatus)
+		}
+
+		patch = jsonpatch.GenerateJsonPath(index, o.remove, oldConditions, o.condition)
+
+		// Setting a condition without --ttl, or removing it, clears any expiry recorded by an earlier --ttl.
+		var expiry *time.Time
+		if o.ttl != 0 {
+			expiresAt := o.now().Add(o.ttl)
+			expiry = &expiresAt
+		}
+
+		annotations := map[string]*string{}
+		if err := updateExpiries(node, map[corev1.NodeConditionType]*time.Time{o.condition.Type: expiry}, annotations); err != nil {
+			return nil, err
+		}
+
+		if err := o.recordAuthor(node, map[corev1.NodeConditionType]string{o.condition.Type: patch.OP}, annotations); err != nil {
+			return nil, err
+		}
+
+		var jsonPath []interface{}
+		if index != -1 {
+			jsonPath = append(jsonPath, jsonpatch.GenerateTestPatch(index, o.condition.Type))
+		}
+
+		jsonPath = append(jsonPath, patch)
+		return append(jsonPath, annotationPatches(node, annotations)...), nil
+	})
+	if node == nil {
+		return err
+	}
+
+	o.recordAudit(node.Name, string(o.condition.Type), patch.OP, oldConditions, patch.Value, err)
 	if err != nil {
 		return err
 	}
 
-	oldConditions, index := findConditionType(node.Status.Conditions, o.condition.Type)
+	fmt.Fprintf(o.Out, "condition status %s has been %sed on node %s\n", o.condition.Type, patch.OP, node.Name)
 
-	if index == -1 && o.remove {
-		return fmt.Errorf("condition type of %s does not exist", o.condition.Type)
-	}
+	o.emitEvent(node, oldConditions, patch.Value)
 
-	patch := jsonpatch.GenerateJsonPath(index, o.remove, oldConditions, o.condition)
+	return nil
+}
 
-	jsonPath := []interface{}{patch}
-	bytePatch, err := json.Marshal(jsonPath)
-	if err != nil {
+// removeMatching removes every condition on the node selected by the type pattern or by
+// --remove-all-custom in a single JSON Patch request.
+func (o *ConditionOptions) removeMatching(nodeName string) error {
+	var indices []int
+	node, err := patchStatus(o.client, nodeName, func(node *corev1.Node) ([]interface{}, error) {
+		var err error
+		indices, err = o.matchingConditions(node.Status.Conditions)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(indices) == 0 {
+			if o.removeAllCustom {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("no condition types matching %s", o.typePattern)
+		}
+
+		expiries := make(map[corev1.NodeConditionType]*time.Time, len(indices))
+		operations := make(map[corev1.NodeConditionType]string, len(indices))
+		for _, index := range indices {
+			expiries[node.Status.Conditions[index].Type] = nil
+			operations[node.Status.Conditions[index].Type] = "remove"
+		}
+
+		annotations := map[string]*string{}
+		if err := updateExpiries(node, expiries, annotations); err != nil {
+			return nil, err
+		}
+
+		if err := o.recordAuthor(node, operations, annotations); err != nil {
+			return nil, err
+		}
+
+		jsonPath := jsonpatch.GenerateRemovePatches(node.Status.Conditions, indices)
+		return append(jsonPath, annotationPatches(node, annotations)...), nil
+	})
+	if node == nil {
 		return err
 	}
 
-	if _, err := o.client.CoreV1().Nodes().Patch(context.Background(), node.Name, types.JSONPatchType, bytePatch, metav1.PatchOptions{}, "status"); err != nil {
+	if len(indices) == 0 {
+		fmt.Fprintf(o.Out, "no custom conditions found on node %s\n", node.Name)
+		return nil
+	}
+
+	for _, index := range indices {
+		o.recordAudit(node.Name, string(node.Status.Conditions[index].Type), "remove", &node.Status.Conditions[index], nil, err)
+	}
+
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("condition status %s has been %sed on node %s\n", o.condition.Type, patch.OP, node.Name)
+	for _, index := range indices {
+		fmt.Fprintf(o.Out, "condition status %s has been removed on node %s\n", node.Status.Conditions[index].Type, node.Name)
+		o.emitEvent(node, &node.Status.Conditions[index], nil)
+	}
 
 	return nil
 }
 
+// matchingConditions returns the indices of the conditions selected for removal by the type
+// pattern or by --remove-all-custom. Conditions rejected by the allow-list, deny-list or team prefixes, or whose
+// policy disallows removal are never selected, and protected conditions are only selected with --force-protected
+// unless the cluster policy protects them.
+// An invalid allow-list or deny-list is returned as an error rather than selecting nothing.
+func (o *ConditionOptions) matchingConditions(conditions []corev1.NodeCondition) ([]int, error) {
+	var indices []int
+	for k, v := range conditions {
+		conditionType := string(v.Type)
+
+		if err := o.config.CheckType(conditionType); err != nil {
+			if config.IsRejected(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if err := o.config.CheckTeam(o.team, conditionType); err != nil {
+			if config.IsRejected(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if o.config.Protected(conditionType) && (!o.forceProtected || o.config.ClusterProtected(conditionType)) {
+			continue
+		}
+
+		if validateRemove(conditionType, o.config) != nil {
+			continue
+		}
+
+		if o.removeAllCustom {
+			if !slices.Contains(config.DefaultProtectedTypes, conditionType) {
+				indices = append(indices, k)
+			}
+			continue
+		}
+
+		ok, err := pattern.Match(o.typePattern, conditionType)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			indices = append(indices, k)
+		}
+	}
+
+	return indices, nil
+}
+
 // readStdinNames reads node names from o.In when it is not a TTY. Each non-empty line
 // is returned as a raw name; normalization happens later in setNodeNames. It returns
 // nil, nil when o.In is an interactive terminal, so interactive invocations are not
 // blocked waiting for input.
 func (o *ConditionOptions) readStdinNames() ([]string, error) {
-	f, ok := o.In.(*os.File)
+	return readStdinNames(o.In)
+}
+
+// readStdinNames reads one raw node name per non-empty line of in, unless in is an
+// interactive terminal.
+func readStdinNames(in io.Reader) ([]string, error) {
+	f, ok := in.(*os.File)
 	if ok && term.IsTerminal(int(f.Fd())) {
 		return nil, nil
 	}
 
 	var names []string
-	scanner := bufio.NewScanner(o.In)
+	scanner := bufio.NewScanner(in)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line != "" {
@@ -308,6 +624,26 @@ func (o *ConditionOptions) readStdinNames() ([]string, error) {
 	return names, nil
 }
 
+// parseConditionStatus parses a condition status case-insensitively. An empty status is Unknown,
+// and keepStatus returns an empty status so GenerateJsonPath keeps the status of the existing condition.
+// Any other value is rejected instead of silently becoming Unknown.
+func parseConditionStatus(status string) (corev1.ConditionStatus, error) {
+	switch {
+	case status == "":
+		return corev1.ConditionUnknown, nil
+	case strings.EqualFold(status, keepStatus):
+		return "", nil
+	case strings.EqualFold(status, string(corev1.ConditionTrue)):
+		return corev1.ConditionTrue, nil
+	case strings.EqualFold(status, string(corev1.ConditionFalse)):
+		return corev1.ConditionFalse, nil
+	case strings.EqualFold(status, string(corev1.ConditionUnknown)):
+		return corev1.ConditionUnknown, nil
+	default:
+		return "", fmt.Errorf("invalid status %q, must be one of true, false, unknown or %s", status, keepStatus)
+	}
+}
+
 // normalizeNodeName strips whitespace and the "node/" or "nodes/" prefixes that
 // kubectl outputs when using -o name (e.g. "node/worker-01" → "worker-01").
 func normalizeNodeName(node string) string {
@@ -329,13 +665,3 @@ func findConditionType(conditions []corev1.NodeCondition, conditionType corev1.N
 
 	return nil, -1
 }
-
-// allowedType checks if a given condition type is in the list of allowed types.
-func allowedType(conditionType string, allowedTypes []string) bool {
-	for _, v := range allowedTypes {
-		if v == conditionType {
-			return true
-		}
-	}
-	return false
-}
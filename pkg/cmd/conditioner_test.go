@@ -1,40 +1,270 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/devbytes-cloud/conditioner/pkg/audit"
 	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/devbytes-cloud/conditioner/pkg/notify"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
+// newCompleteCommand returns the conditioner command with the given flags set, ready to be passed to Complete.
+func newCompleteCommand(t *testing.T, flags map[string]string) *cobra.Command {
+	t.Helper()
+
+	c := NewCmdCondition(genericiooptions.IOStreams{})
+	for name, value := range flags {
+		require.NoError(t, c.Flags().Set(name, value))
+	}
+
+	return c
+}
+
+// newCompleteOptions returns ConditionOptions completed against the test kubeconfig and a fake client
+// granting every permission, so Complete never talks to a real cluster.
+func newCompleteOptions(t *testing.T, streams genericiooptions.IOStreams) *ConditionOptions {
+	t.Helper()
+
+	o := NewConditionOptions(streams)
+	o.configFlags = newTestConfigFlags(t)
+	o.client = newAccessReviewClient(func(*authorizationv1.ResourceAttributes) bool { return true }, nil)
+
+	return o
+}
+
 func TestComplete(t *testing.T) {
 	streams := genericiooptions.IOStreams{}
-	o := NewConditionOptions(streams)
+	o := newCompleteOptions(t, streams)
 
-	// Setup Cobra command with flags
-	c := &cobra.Command{}
-	c.Flags().String("type", "Ready", "")
-	c.Flags().String("status", "true", "")
-	c.Flags().String("reason", "KubeletReady", "")
-	c.Flags().String("message", "kubelet is posting ready status", "")
-	c.Flags().Bool("remove", false, "remove the condition") // Make sure to define the 'remove' flag
+	c := newCompleteCommand(t, map[string]string{
+		"type":    "NodeMaintenance",
+		"status":  "true",
+		"reason":  "MaintenanceScheduled",
+		"message": "node is scheduled for maintenance",
+	})
 
 	err := o.Complete(c, []string{"test-node"}, &config.Config{})
 	assert.NoError(t, err)
 
 	// Assert the fields are set correctly
-	assert.Equal(t, "Ready", string(o.condition.Type))
+	assert.Equal(t, "NodeMaintenance", string(o.condition.Type))
 	assert.Equal(t, corev1.ConditionTrue, o.condition.Status)
-	assert.Equal(t, "KubeletReady", o.condition.Reason)
-	assert.Equal(t, "kubelet is posting ready status", o.condition.Message)
+	assert.Equal(t, "MaintenanceScheduled", o.condition.Reason)
+	assert.Equal(t, "node is scheduled for maintenance", o.condition.Message)
+}
+
+func TestCompleteProtectedTypes(t *testing.T) {
+	t.Run("Error: default protected type", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Ready", "status": "true"})
+
+		err := o.Complete(c, nil, &config.Config{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "condition Ready is protected")
+	})
+
+	t.Run("Error: configured protected type", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "remove": "true"})
+
+		err := o.Complete(c, nil, &config.Config{ProtectedTypes: []string{"NodeMaintenance"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "condition NodeMaintenance is protected")
+	})
+
+	t.Run("Success: force protected", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Ready", "status": "true", "force-protected": "true"})
+
+		require.NoError(t, o.Complete(c, nil, &config.Config{}))
+		assert.Equal(t, corev1.NodeReady, o.condition.Type)
+	})
+
+	t.Run("Error: cluster protected type with force protected", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		_, err := o.client.CoreV1().ConfigMaps("kube-system").Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "conditioner-policy"},
+			Data:       map[string]string{config.ClusterPolicyKey: "protected-types: [Ready]\n"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		c := newCompleteCommand(t, map[string]string{"type": "Ready", "status": "true", "force-protected": "true"})
+
+		err = o.Complete(c, nil, &config.Config{ProtectedTypes: []string{}})
+		assert.EqualError(t, err, "condition Ready is protected by the cluster policy, it cannot be modified even with --force-protected")
+	})
+
+	t.Run("Success: protection disabled", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Ready", "status": "true"})
+
+		require.NoError(t, o.Complete(c, nil, &config.Config{ProtectedTypes: []string{}}))
+	})
+}
+
+func TestCompleteTypePattern(t *testing.T) {
+	t.Run("Error: pattern without remove", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "team-a.example.com/*"})
+
+		err := o.Complete(c, nil, &config.Config{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can only be used with --remove")
+	})
+
+	t.Run("Error: invalid pattern", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "/(Ready/", "remove": "true"})
+
+		err := o.Complete(c, nil, &config.Config{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid regular expression")
+	})
+
+	t.Run("Success: pattern with remove", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "team-a.example.com/*", "remove": "true"})
+
+		require.NoError(t, o.Complete(c, nil, &config.Config{}))
+		assert.Equal(t, "team-a.example.com/*", o.typePattern)
+		assert.True(t, o.remove)
+	})
+
+	t.Run("Success: remove all custom", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"remove-all-custom": "true"})
+
+		require.NoError(t, o.Complete(c, nil, &config.Config{}))
+		assert.True(t, o.removeAllCustom)
+		assert.True(t, o.remove)
+	})
+}
+
+func TestMatchingConditions(t *testing.T) {
+	conditions := []corev1.NodeCondition{
+		{Type: corev1.NodeReady},
+		{Type: "team-a.example.com/Healthy"},
+		{Type: corev1.NodeDiskPressure},
+		{Type: "team-b.example.com/Healthy"},
+		{Type: "team-a.example.com/Draining"},
+	}
+
+	clusterProtected, err := (&config.Config{}).WithClusterPolicy(config.Profile{ProtectedTypes: []string{string(corev1.NodeDiskPressure)}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		opts    ConditionOptions
+		indices []int
+	}{
+		{
+			name:    "Glob",
+			opts:    ConditionOptions{typePattern: "team-a.example.com/*", changeOptions: changeOptions{config: &config.Config{}}},
+			indices: []int{1, 4},
+		},
+		{
+			name:    "Regex",
+			opts:    ConditionOptions{typePattern: "/Healthy$/", changeOptions: changeOptions{config: &config.Config{}}},
+			indices: []int{1, 3},
+		},
+		{
+			name:    "Remove all custom",
+			opts:    ConditionOptions{removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{}}},
+			indices: []int{1, 3, 4},
+		},
+		{
+			name:    "Pattern skips protected types",
+			opts:    ConditionOptions{typePattern: "*Pressure", changeOptions: changeOptions{config: &config.Config{}}},
+			indices: nil,
+		},
+		{
+			name:    "Pattern with force protected",
+			opts:    ConditionOptions{typePattern: "*Pressure", forceProtected: true, changeOptions: changeOptions{config: &config.Config{}}},
+			indices: []int{2},
+		},
+		{
+			name:    "Pattern with force protected skips cluster protected types",
+			opts:    ConditionOptions{typePattern: "*Pressure", forceProtected: true, changeOptions: changeOptions{config: clusterProtected}},
+			indices: nil,
+		},
+		{
+			name:    "Remove all custom skips configured protected types",
+			opts:    ConditionOptions{removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{ProtectedTypes: []string{"team-a.example.com/Healthy"}}}},
+			indices: []int{3, 4},
+		},
+		{
+			name:    "Remove all custom respects allow-list",
+			opts:    ConditionOptions{removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{AllowList: []string{"team-b.example.com/Healthy"}}}},
+			indices: []int{3},
+		},
+		{
+			name:    "Glob matches prefixed types",
+			opts:    ConditionOptions{typePattern: "*Healthy", changeOptions: changeOptions{config: &config.Config{}}},
+			indices: []int{1, 3},
+		},
+		{
+			name:    "Remove all custom respects prefixed deny-list glob",
+			opts:    ConditionOptions{removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{DenyList: []string{"*Draining"}}}},
+			indices: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indices, err := tt.opts.matchingConditions(conditions)
+			require.NoError(t, err)
+			assert.Equal(t, tt.indices, indices)
+		})
+	}
+
+	t.Run("Error: invalid deny-list", func(t *testing.T) {
+		o := ConditionOptions{removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{DenyList: []string{"/(broken/"}}}}
+
+		_, err := o.matchingConditions(conditions)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deny-list: invalid regular expression /(broken/")
+	})
+}
+
+func TestSubcommandNodeNames(t *testing.T) {
+	root := NewCmdCondition(genericiooptions.IOStreams{})
+
+	for _, name := range []string{"prune", "reap", "config", "history"} {
+		t.Run(name, func(t *testing.T) {
+			found, _, err := root.Find([]string{name})
+			require.NoError(t, err)
+			assert.Equal(t, name, found.Name(), "the bare name runs the subcommand")
+
+			found, args, err := root.Find([]string{"node/" + name})
+			require.NoError(t, err)
+			assert.Same(t, root, found, "the node/ prefix conditions the node")
+			assert.Equal(t, []string{"node/" + name}, args)
+			assert.Equal(t, name, normalizeNodeName(args[0]))
+		})
+	}
 }
 
 func TestSetNodeNames(t *testing.T) {
@@ -145,3 +375,539 @@ func TestReadStdinNames_ScannerError(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "reading stdin:")
 }
+
+func TestRunForNodeRemoveMatching(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady},
+				{Type: "team-a.example.com/Healthy"},
+				{Type: corev1.NodeDiskPressure},
+				{Type: "team-a.example.com/Draining"},
+			},
+		},
+	}
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.remove = true
+	o.removeAllCustom = true
+	o.config = &config.Config{}
+
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.NodeCondition{{Type: corev1.NodeReady}, {Type: corev1.NodeDiskPressure}}, got.Status.Conditions)
+	assert.Contains(t, out.String(), "team-a.example.com/Healthy has been removed on node worker-01")
+	assert.Contains(t, out.String(), "team-a.example.com/Draining has been removed on node worker-01")
+}
+
+func TestRunForNodeRemoveMatchingRetriesMovedConditions(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady},
+				{Type: "team-a.example.com/Healthy"},
+			},
+		},
+	}
+
+	client := fake.NewClientset(node)
+
+	// The kubelet adds a condition in front of the others between the Get and the first Patch, which the
+	// API server rejects as the test operation guarding the removal no longer holds.
+	var patches []string
+	client.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patches = append(patches, string(action.(k8stesting.PatchAction).GetPatch()))
+		if len(patches) > 1 {
+			return false, nil, nil
+		}
+
+		moved := node.DeepCopy()
+		moved.Status.Conditions = append([]corev1.NodeCondition{{Type: corev1.NodeMemoryPressure}}, moved.Status.Conditions...)
+		require.NoError(t, client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("nodes"), moved, ""))
+
+		return true, nil, apierrors.NewGenericServerResponse(http.StatusUnprocessableEntity, "patch", corev1.Resource("nodes"), "worker-01", "test failed", 0, false)
+	})
+
+	o := NewConditionOptions(genericiooptions.IOStreams{Out: io.Discard, ErrOut: io.Discard})
+	o.client = client
+	o.remove = true
+	o.removeAllCustom = true
+	o.config = &config.Config{}
+
+	require.NoError(t, o.runForNode("worker-01"))
+
+	require.Len(t, patches, 2)
+	assert.Contains(t, patches[0], `{"op":"test","path":"/status/conditions/1/type","value":"team-a.example.com/Healthy"},{"op":"remove","path":"/status/conditions/1"`)
+	assert.Contains(t, patches[1], `{"op":"test","path":"/status/conditions/2/type","value":"team-a.example.com/Healthy"},{"op":"remove","path":"/status/conditions/2"`)
+
+	got, err := client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.NodeCondition{{Type: corev1.NodeMemoryPressure}, {Type: corev1.NodeReady}}, got.Status.Conditions)
+}
+
+func TestRunForNodeTTL(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady}}},
+	}
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "UnderInvestigation", Status: corev1.ConditionTrue}
+	o.ttl = 4 * time.Hour
+
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	expiries, err := readExpiries(got)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(4*time.Hour), expiries["UnderInvestigation"], time.Minute)
+
+	// Setting the condition again without a TTL clears the expiry.
+	o.ttl = 0
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err = o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, got.Annotations, expiryAnnotation)
+}
+
+func TestRunForNodeRecordAuthor(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady}}},
+	}
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "UnderInvestigation", Status: corev1.ConditionTrue}
+	o.author = &authorRecord{Who: "jane@example.com", Host: "laptop", Command: "kubectl-conditioner worker-01"}
+	o.ttl = time.Hour
+
+	// The node has no annotations, so the expiry and author annotations are added in a single object.
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, got.Annotations, expiryAnnotation)
+
+	authors, err := readAuthors(got)
+	require.NoError(t, err)
+	record := authors["UnderInvestigation"]
+	assert.Equal(t, "jane@example.com", record.Who)
+	assert.Equal(t, "laptop", record.Host)
+	assert.Equal(t, "kubectl-conditioner worker-01", record.Command)
+	assert.Equal(t, "add", record.Operation)
+	assert.WithinDuration(t, time.Now(), record.When, time.Minute)
+
+	// Removing the condition records the removal.
+	o.remove = true
+	o.ttl = 0
+	o.author.Who = "john@example.com"
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err = o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, got.Annotations, expiryAnnotation)
+
+	authors, err = readAuthors(got)
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", authors["UnderInvestigation"].Who)
+	assert.Equal(t, "remove", authors["UnderInvestigation"].Operation)
+}
+
+func TestRunAuditLog(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "NodeMaintenance", Status: corev1.ConditionFalse}}},
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled"}
+	o.nodeNames = []string{"worker-01", "worker-02"}
+	o.auditLog = audit.New(path, 1024*1024, 1)
+	o.actor = "jane@example.com"
+	o.kubeContext = "prod"
+	o.server = "https://prod.example.com"
+	o.justification = "INC-1234 hardware replacement"
+
+	err := o.Run()
+	assert.EqualError(t, err, `worker-02: nodes "worker-02" not found`)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entries [2]audit.Entry
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &entries[i]))
+	}
+
+	assert.Equal(t, "worker-01", entries[0].Node)
+	assert.Equal(t, "prod", entries[0].Context)
+	assert.Equal(t, "https://prod.example.com", entries[0].Server)
+	assert.Equal(t, "jane@example.com", entries[0].Actor)
+	assert.Equal(t, "NodeMaintenance", entries[0].ConditionType)
+	assert.Equal(t, "replace", entries[0].Operation)
+	assert.Equal(t, corev1.ConditionFalse, entries[0].Before.Status)
+	assert.Equal(t, corev1.ConditionTrue, entries[0].After.Status)
+	assert.Equal(t, audit.ResultSuccess, entries[0].Result)
+	assert.Equal(t, "INC-1234 hardware replacement", entries[0].Justification)
+
+	assert.Equal(t, "worker-02", entries[1].Node)
+	assert.Equal(t, "NodeMaintenance", entries[1].ConditionType)
+	assert.Equal(t, audit.ResultFailure, entries[1].Result)
+	assert.Equal(t, `nodes "worker-02" not found`, entries[1].Error)
+}
+
+func TestRunStopsWhenAuditLogFails(t *testing.T) {
+	nodes := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-01"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-02"}},
+	}
+
+	// The audit log path is a directory, so writing the entries of the first node fails.
+	path := t.TempDir()
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(nodes...)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue}
+	o.nodeNames = []string{"worker-01", "worker-02"}
+	o.auditLog = audit.New(path, 1024*1024, 1)
+
+	err := o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "writing audit log, no further nodes are changed")
+
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-02", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, node.Status.Conditions)
+}
+
+func TestCompleteChecksAuditLog(t *testing.T) {
+	blocked := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocked, nil, 0o600))
+
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+	err := o.Complete(c, nil, &config.Config{AuditLog: filepath.Join(blocked, "audit.log")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit log: ")
+}
+
+func TestCompleteValidatesWebhooks(t *testing.T) {
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+	err := o.Complete(c, nil, &config.Config{Webhooks: []config.Webhook{{Name: "slack", URL: "https://hooks.example.com/T000", Template: "{{.Changes"}}})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid webhooks:\nwebhooks[0].template: ")
+}
+
+func TestRunNotifiesWebhooks(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "NodeMaintenance", Status: corev1.ConditionFalse}}},
+	}
+
+	streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled"}
+	o.nodeNames = []string{"worker-01", "worker-02"}
+	o.actor = "jane@example.com"
+	o.notifier = notify.New([]config.Webhook{{Name: "on-call", URL: server.URL, Format: config.WebhookFormatSlack}})
+
+	assert.Error(t, o.Run())
+	assert.Empty(t, errOut.String())
+
+	// Only the successful change on worker-01 is notified.
+	assert.Equal(t, []string{`{"text":"condition NodeMaintenance on node worker-01 changed from False to True by jane@example.com"}`}, bodies)
+}
+
+func TestParseConditionStatus(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    corev1.ConditionStatus
+		wantErr bool
+	}{
+		{"", corev1.ConditionUnknown, false},
+		{"true", corev1.ConditionTrue, false},
+		{"True", corev1.ConditionTrue, false},
+		{"FALSE", corev1.ConditionFalse, false},
+		{"unknown", corev1.ConditionUnknown, false},
+		{"Keep", "", false},
+		{"flase", "", true},
+		{"yes", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseConditionStatus(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid status")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompleteInvalidStatus(t *testing.T) {
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "flase"})
+
+	err := o.Complete(c, nil, &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid status "flase"`)
+}
+
+func TestRunForNodeKeepStatus(t *testing.T) {
+	transitioned := metav1.NewTime(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled", Message: "scheduled", LastTransitionTime: transitioned},
+		}},
+	}
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.condition = &corev1.NodeCondition{Type: "NodeMaintenance", Message: "postponed"}
+
+	require.NoError(t, o.runForNode("worker-01"))
+
+	got, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, "MaintenanceScheduled", got.Status.Conditions[0].Reason)
+	assert.Equal(t, "postponed", got.Status.Conditions[0].Message)
+	assert.True(t, transitioned.Equal(&got.Status.Conditions[0].LastTransitionTime), "the status did not change, so neither may the transition time")
+
+	o.condition = &corev1.NodeCondition{Type: "Missing"}
+	err = o.runForNode("worker-01")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can only be used to update an existing condition")
+}
+
+func TestCompleteEventsPermission(t *testing.T) {
+	conf := &config.Config{Events: true}
+
+	t.Run("Success: events are recorded with the permission", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+		require.NoError(t, o.Complete(c, nil, conf))
+		assert.True(t, o.events)
+	})
+
+	t.Run("Success: a missing permission only warns", func(t *testing.T) {
+		streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+		o := newCompleteOptions(t, streams)
+		o.client = newAccessReviewClient(func(attributes *authorizationv1.ResourceAttributes) bool {
+			return attributes.Resource != "events"
+		}, nil)
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+		require.NoError(t, o.Complete(c, nil, conf))
+		assert.False(t, o.events)
+		assert.Equal(t, "warning: changes are not recorded as Events, you are missing the permission to create events.events.k8s.io in namespace default\n", errOut.String())
+	})
+
+	t.Run("Error: access review fails", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		o.client = newAccessReviewClient(func(*authorizationv1.ResourceAttributes) bool { return true }, errors.New("connection refused"))
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+		err := o.Complete(c, nil, conf)
+		assert.EqualError(t, err, "checking permission to create events.events.k8s.io in namespace default: connection refused")
+	})
+}
+
+func TestCompleteValidatesReason(t *testing.T) {
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true", "reason": "not camel case"})
+
+	err := o.Complete(c, nil, &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be a CamelCase identifier")
+}
+
+func TestCompleteValidatesMessageBeforeWhoAmI(t *testing.T) {
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+	conf := &config.Config{WhoAmI: true, Policies: map[string]config.TypePolicy{"NodeMaintenance": {RequireMessage: true}}}
+
+	err := o.Complete(c, nil, conf)
+	require.Error(t, err)
+	assert.Equal(t, "condition NodeMaintenance requires a --message", err.Error())
+}
+
+func TestRunForNodeNodeSelector(t *testing.T) {
+	gpu := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-01", Labels: map[string]string{"accelerator": "nvidia"}},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady}}},
+	}
+	cpu := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "cpu-01"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady}}},
+	}
+
+	conf := &config.Config{Policies: map[string]config.TypePolicy{"GPUHealthy": {NodeSelector: "accelerator=nvidia"}}}
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := newCompleteOptions(t, streams)
+	c := newCompleteCommand(t, map[string]string{"type": "GPUHealthy", "status": "true"})
+	require.NoError(t, o.Complete(c, nil, conf))
+	o.client = fake.NewClientset(gpu, cpu)
+
+	require.NoError(t, o.runForNode("gpu-01"))
+
+	err := o.runForNode("cpu-01")
+	require.Error(t, err)
+	assert.Equal(t, `condition GPUHealthy may only be set on nodes matching "accelerator=nvidia"`, err.Error())
+}
+
+func TestCompleteDisallowRemove(t *testing.T) {
+	o := newCompleteOptions(t, genericiooptions.IOStreams{})
+	c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "remove": "true"})
+
+	err := o.Complete(c, nil, &config.Config{Policies: map[string]config.TypePolicy{"NodeMaintenance": {DisallowRemove: true}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may not be removed")
+}
+
+func TestCompleteAllowAndDenyLists(t *testing.T) {
+	conf := &config.Config{
+		AllowList: []string{"team-a.example.com/*"},
+		DenyList:  []string{"team-a.example.com/Legacy*"},
+	}
+
+	t.Run("Success: glob allow-list", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "team-a.example.com/Healthy", "status": "true"})
+
+		require.NoError(t, o.Complete(c, nil, conf))
+	})
+
+	t.Run("Error: not in allow-list", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "team-b.example.com/Healthy", "status": "true"})
+
+		err := o.Complete(c, nil, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not in allow-list")
+	})
+
+	t.Run("Error: deny-list takes precedence", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "team-a.example.com/LegacyProbe", "status": "true"})
+
+		err := o.Complete(c, nil, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is in deny-list")
+	})
+
+	t.Run("Pattern removal skips denied types", func(t *testing.T) {
+		o := ConditionOptions{typePattern: "team-a.example.com/*", changeOptions: changeOptions{config: conf}}
+
+		indices, err := o.matchingConditions([]corev1.NodeCondition{
+			{Type: "team-a.example.com/Healthy"},
+			{Type: "team-a.example.com/LegacyProbe"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, indices)
+	})
+}
+
+func TestCompleteTeams(t *testing.T) {
+	conf := &config.Config{
+		Teams: map[string]config.Team{
+			"storage": {Prefix: "storage.example.com"},
+			"network": {Prefix: "network.example.com"},
+		},
+	}
+
+	t.Run("Success: team prefix applied", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Healthy", "status": "true", "team": "storage"})
+
+		require.NoError(t, o.Complete(c, nil, conf))
+		assert.Equal(t, corev1.NodeConditionType("storage.example.com/Healthy"), o.condition.Type)
+	})
+
+	t.Run("Success: default team", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Healthy", "status": "true"})
+
+		withDefault := *conf
+		withDefault.DefaultTeam = "network"
+		require.NoError(t, o.Complete(c, nil, &withDefault))
+		assert.Equal(t, corev1.NodeConditionType("network.example.com/Healthy"), o.condition.Type)
+	})
+
+	t.Run("Error: other team's prefix", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "network.example.com/Healthy", "status": "true", "team": "storage"})
+
+		err := o.Complete(c, nil, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "team storage may only use condition types prefixed with storage.example.com/")
+	})
+
+	t.Run("Error: unknown team", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "Healthy", "status": "true", "team": "compute"})
+
+		err := o.Complete(c, nil, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown team compute")
+	})
+
+	t.Run("Remove all custom only selects the team's conditions", func(t *testing.T) {
+		o := ConditionOptions{removeAllCustom: true, team: "storage", changeOptions: changeOptions{config: conf}}
+
+		indices, err := o.matchingConditions([]corev1.NodeCondition{
+			{Type: "storage.example.com/Healthy"},
+			{Type: "network.example.com/Healthy"},
+			{Type: "Unowned"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, indices)
+	})
+}
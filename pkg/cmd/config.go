@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	configInitExample = `
+# Create a configuration file with the built-in defaults
+kubectl conditioner config init
+
+# Create a configuration file at a specific path, replacing an existing one
+kubectl conditioner config init --config ./conditioner.yaml --force
+`
+
+	configViewExample = `
+# Print the effective configuration of the current kubeconfig context, with the built-in defaults filled in
+kubectl conditioner config view
+
+# Print the effective configuration of another kubeconfig context, with its profile applied
+kubectl conditioner config view --context prod
+
+# Print the effective configuration as JSON
+kubectl conditioner config view -o json
+`
+
+	configSetExample = `
+# Prepend the user's identity to condition messages
+kubectl conditioner config set prepend-whoami true
+
+# Replace the allow-list
+kubectl conditioner config set allow-list '[NodeMaintenance, team-a.example.com/*]'
+
+# Set a field of a policy, escaping the dots of the condition type
+kubectl conditioner config set 'policies.storage\.example\.com/Healthy.require-message' true
+`
+
+	configUnsetExample = `
+# Remove the allow-list, allowing every condition type
+kubectl conditioner config unset allow-list
+`
+
+	configValidateExample = `
+# Check the configuration file for unknown keys and invalid values
+kubectl conditioner config validate
+`
+)
+
+// ConfigOptions is a struct that holds the configuration for the config command and its subcommands.
+type ConfigOptions struct {
+	// IOStreams provides the standard names for iostreams. This is useful for embedding and for unit testing.
+	genericiooptions.IOStreams
+
+	// fs is the filesystem the configuration file is read from and written to.
+	fs config.Filesystem
+
+	// override is the path of the configuration file given with --config.
+	override string
+
+	// path is the resolved path of the configuration file.
+	path string
+
+	// configFlags selects the kubeconfig context whose profile config view applies.
+	configFlags *genericclioptions.ConfigFlags
+}
+
+// NewConfigOptions is a function that creates a new ConfigOptions.
+func NewConfigOptions(streams genericiooptions.IOStreams) *ConfigOptions {
+	return &ConfigOptions{
+		IOStreams:   streams,
+		fs:          config.FS{},
+		configFlags: genericclioptions.NewConfigFlags(true),
+	}
+}
+
+// NewCmdConfig returns a cobra.Command that implements the config subcommand.
+func NewCmdConfig(streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewConfigOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "config",
+		Short:        "Manage the conditioner configuration file.",
+		SilenceUsage: true,
+	}
+
+	initCmd := &cobra.Command{
+		Use:          "init",
+		Short:        "Create a configuration file with the built-in defaults.",
+		Example:      configInitExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+
+			force, err := c.Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+
+			return o.RunInit(force)
+		},
+	}
+	initCmd.Flags().BoolP("force", "f", false, "Overwrite an existing configuration file")
+
+	viewCmd := &cobra.Command{
+		Use:          "view",
+		Short:        "Print the effective configuration.",
+		Example:      configViewExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+
+			output, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			return o.RunView(output)
+		},
+	}
+	viewCmd.Flags().StringP("output", "o", "yaml", "Output format [yaml, json]")
+	o.configFlags.AddFlags(viewCmd.Flags())
+
+	setCmd := &cobra.Command{
+		Use:          "set KEY VALUE",
+		Short:        "Set a value in the configuration file.",
+		Example:      configSetExample,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+
+			return o.RunSet(args[0], args[1])
+		},
+	}
+
+	unsetCmd := &cobra.Command{
+		Use:          "unset KEY",
+		Short:        "Remove a value from the configuration file.",
+		Example:      configUnsetExample,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+
+			return o.RunUnset(args[0])
+		},
+	}
+
+	validateCmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "Check the configuration file for unknown keys and invalid values.",
+		Example:      configValidateExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			if err := o.Complete(c); err != nil {
+				return err
+			}
+
+			return o.RunValidate()
+		},
+	}
+
+	cmd.AddCommand(initCmd, viewCmd, setCmd, unsetCmd, validateCmd)
+
+	return cmd
+}
+
+// Complete resolves the path of the configuration file from the --config flag,
+// the CONDITIONER_CONFIG environment variable or the default locations.
+func (o *ConfigOptions) Complete(c *cobra.Command) error {
+	var err error
+	o.override, err = c.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	o.path, err = config.Path(o.fs, o.override)
+	return err
+}
+
+// RunInit writes the built-in default configuration to the configuration file.
+// An existing configuration file is only overwritten when force is set.
+func (o *ConfigOptions) RunInit(force bool) error {
+	exists, err := config.Exists(o.fs, o.path)
+	if err != nil {
+		return err
+	}
+
+	if exists && !force {
+		return fmt.Errorf("configuration file %s already exists, use --force to overwrite it", o.path)
+	}
+
+	if err := config.Write(o.fs, o.path, config.Default()); err != nil {
+		return fmt.Errorf("writing configuration file %s: %w", o.path, err)
+	}
+
+	fmt.Fprintf(o.Out, "configuration file written to %s\n", o.path)
+
+	return nil
+}
+
+// RunView prints the effective configuration, the configuration file with the profile of the kubeconfig context
+// applied, as the other commands do, merged with the built-in defaults. The cluster policy is not applied.
+func (o *ConfigOptions) RunView(output string) error {
+	conf, err := config.Load(o.fs, o.override)
+	if err != nil {
+		return err
+	}
+
+	conf, err = profileConfig(o.configFlags, conf)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(conf.Effective(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.JSONToYAML(out)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of yaml or json", output)
+	}
+
+	_, err = o.Out.Write(out)
+	return err
+}
+
+// RunSet sets the value at key in the configuration file, creating the file if it does not exist.
+func (o *ConfigOptions) RunSet(key, value string) error {
+	return o.edit(func(conf *config.Config) (*config.Config, error) {
+		return config.Set(conf, key, value)
+	})
+}
+
+// RunUnset removes the value at key from the configuration file.
+func (o *ConfigOptions) RunUnset(key string) error {
+	return o.edit(func(conf *config.Config) (*config.Config, error) {
+		return config.Unset(conf, key)
+	})
+}
+
+// RunValidate strictly decodes the configuration file and validates its values.
+func (o *ConfigOptions) RunValidate() error {
+	data, err := o.fs.ReadFile(o.path)
+	if err != nil {
+		return err
+	}
+
+	conf, err := config.Parse(data, o.path)
+	if err != nil {
+		return err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return fmt.Errorf("%s is invalid:\n%w", o.path, err)
+	}
+
+	fmt.Fprintf(o.Out, "configuration file %s is valid\n", o.path)
+
+	return nil
+}
+
+// edit reads the configuration file, or the built-in defaults when it does not exist,
+// applies the change and writes the validated result back to the configuration file.
+func (o *ConfigOptions) edit(change func(conf *config.Config) (*config.Config, error)) error {
+	exists, err := config.Exists(o.fs, o.path)
+	if err != nil {
+		return err
+	}
+
+	conf := config.Default()
+	if exists {
+		conf, err = config.Read(o.fs, o.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	conf, err = change(conf)
+	if err != nil {
+		return err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.Write(o.fs, o.path, conf); err != nil {
+		return fmt.Errorf("writing configuration file %s: %w", o.path, err)
+	}
+
+	fmt.Fprintf(o.Out, "configuration file %s updated\n", o.path)
+
+	return nil
+}
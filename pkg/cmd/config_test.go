@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestConfigInit(t *testing.T) {
+	path := "/xdg/conditioner/config.yaml"
+
+	t.Run("Success: creates the config file", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().Stat(path).Return(nil, os.ErrNotExist).Once()
+		mockFS.EXPECT().MkdirAll("/xdg/conditioner", os.FileMode(0o755)).Return(nil).Once()
+		mockFS.EXPECT().WriteFile(path, mock.Anything, os.FileMode(0o644)).Return(nil).Once()
+
+		o := NewConfigOptions(streams)
+		o.fs = mockFS
+		o.path = path
+
+		require.NoError(t, o.RunInit(false))
+		assert.Equal(t, "configuration file written to /xdg/conditioner/config.yaml\n", out.String())
+	})
+
+	t.Run("Error: config file exists", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().Stat(path).Return(nil, nil).Once()
+
+		o := NewConfigOptions(genericiooptions.IOStreams{})
+		o.fs = mockFS
+		o.path = path
+
+		err := o.RunInit(false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists, use --force to overwrite it")
+	})
+
+	t.Run("Error: read-only home directory", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().Stat(path).Return(nil, nil).Once()
+		mockFS.EXPECT().MkdirAll("/xdg/conditioner", os.FileMode(0o755)).Return(os.ErrPermission).Once()
+
+		o := NewConfigOptions(genericiooptions.IOStreams{})
+		o.fs = mockFS
+		o.path = path
+
+		err := o.RunInit(true)
+		assert.ErrorIs(t, err, os.ErrPermission)
+	})
+}
+
+func TestConfigView(t *testing.T) {
+	path := "/etc/conditioner.json"
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+
+	mockFS := mocks.NewMockFilesystem(t)
+	mockFS.EXPECT().ReadFile(path).Return([]byte(`{"allow-list": ["NodeMaintenance"]}`), nil).Once()
+
+	o := NewConfigOptions(streams)
+	o.fs = mockFS
+	o.override = path
+	o.path = path
+	o.configFlags = newTestConfigFlags(t)
+
+	require.NoError(t, o.RunView("yaml"))
+	assert.Contains(t, out.String(), "allow-list:\n- NodeMaintenance\n")
+	assert.Contains(t, out.String(), "max-message-length: 32768\n")
+	assert.Contains(t, out.String(), "- NetworkUnavailable\n")
+}
+
+func TestConfigViewProfile(t *testing.T) {
+	path := "/etc/conditioner.json"
+	data := []byte(`{"allow-list": ["NodeMaintenance"], "profiles": {"prod": {"max-nodes": 5}}}`)
+
+	t.Run("Success: current context without a profile", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().ReadFile(path).Return(data, nil).Once()
+
+		o := NewConfigOptions(streams)
+		o.fs = mockFS
+		o.override = path
+		o.configFlags = newTestConfigFlags(t)
+
+		require.NoError(t, o.RunView("json"))
+		assert.NotContains(t, out.String(), "\n  \"max-nodes\": 5")
+	})
+
+	t.Run("Success: --context applies its profile", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().ReadFile(path).Return(data, nil).Once()
+
+		o := NewConfigOptions(streams)
+		o.fs = mockFS
+		o.override = path
+		o.configFlags = newTestConfigFlags(t)
+		contextName := "prod"
+		o.configFlags.Context = &contextName
+
+		require.NoError(t, o.RunView("json"))
+		assert.Contains(t, out.String(), "\n  \"max-nodes\": 5")
+	})
+}
+
+func TestConfigSet(t *testing.T) {
+	path := "/etc/conditioner.json"
+
+	t.Run("Success: updates the config file", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().Stat(path).Return(nil, nil).Once()
+		mockFS.EXPECT().ReadFile(path).Return([]byte(`{"allow-list": ["NodeMaintenance"]}`), nil).Once()
+		mockFS.EXPECT().MkdirAll("/etc", os.FileMode(0o755)).Return(nil).Once()
+		mockFS.EXPECT().WriteFile(path, mock.MatchedBy(func(data []byte) bool {
+			return strings.Contains(string(data), `"prepend-whoami": true`) && strings.Contains(string(data), `"NodeMaintenance"`)
+		}), os.FileMode(0o644)).Return(nil).Once()
+
+		o := NewConfigOptions(streams)
+		o.fs = mockFS
+		o.path = path
+
+		require.NoError(t, o.RunSet("prepend-whoami", "true"))
+		assert.Equal(t, "configuration file /etc/conditioner.json updated\n", out.String())
+	})
+
+	t.Run("Error: invalid regex is not written", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().Stat(path).Return(nil, os.ErrNotExist).Once()
+
+		o := NewConfigOptions(genericiooptions.IOStreams{})
+		o.fs = mockFS
+		o.path = path
+
+		err := o.RunSet("deny-list", "['/(broken/']")
+		assert.ErrorContains(t, err, "deny-list: invalid regular expression")
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	path := "/etc/conditioner.json"
+
+	t.Run("Success", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().ReadFile(path).Return([]byte(`{"allow-list": ["team-a.example.com/*"]}`), nil).Once()
+
+		o := NewConfigOptions(streams)
+		o.fs = mockFS
+		o.path = path
+
+		require.NoError(t, o.RunValidate())
+		assert.Equal(t, "configuration file /etc/conditioner.json is valid\n", out.String())
+	})
+
+	t.Run("Error: unknown key", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().ReadFile(path).Return([]byte(`{"allowlist": ["NodeMaintenance"]}`), nil).Once()
+
+		o := NewConfigOptions(genericiooptions.IOStreams{})
+		o.fs = mockFS
+		o.path = path
+
+		assert.ErrorContains(t, o.RunValidate(), `unknown field "allowlist"`)
+	})
+
+	t.Run("Error: bad regex", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().ReadFile(path).Return([]byte(`{"allow-list": ["/(broken/"]}`), nil).Once()
+
+		o := NewConfigOptions(genericiooptions.IOStreams{})
+		o.fs = mockFS
+		o.path = path
+
+		assert.ErrorContains(t, o.RunValidate(), "allow-list: invalid regular expression /(broken/")
+	})
+}
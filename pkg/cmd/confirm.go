@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// confirmationSampleSize is the number of node names shown in the confirmation prompt.
+const confirmationSampleSize int = 5
+
+// confirm asks the user to confirm the action on the nodes when it targets more nodes than the confirmation
+// threshold, or always when always is set, e.g. for removals. It returns an error if the user declines, or if
+// confirmation is required but no terminal is available to ask, in which case --yes has to be provided.
+func (o *changeOptions) confirm(action string, nodeNames []string, always bool) error {
+	if o.yes || (!always && len(nodeNames) <= o.config.ConfirmationThreshold()) {
+		return nil
+	}
+
+	terminal, err := o.openTerminal()
+	if err != nil {
+		return fmt.Errorf("confirmation is required to %s on %d node(s) but no terminal is available, use --yes to proceed", action, len(nodeNames))
+	}
+	defer terminal.Close()
+
+	sample := nodeNames
+	if len(sample) > confirmationSampleSize {
+		sample = sample[:confirmationSampleSize]
+	}
+
+	more := ""
+	if len(nodeNames) > len(sample) {
+		more = fmt.Sprintf(" and %d more", len(nodeNames)-len(sample))
+	}
+
+	fmt.Fprintf(o.ErrOut, "About to %s on %d node(s): %s%s\nContinue? [y/N]: ", action, len(nodeNames), strings.Join(sample, ", "), more)
+
+	answer, err := bufio.NewReader(terminal).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("reading confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("aborted, no changes were made")
+	}
+}
+
+// describe describes the change the command makes for the confirmation prompt.
+func (o *ConditionOptions) describe() string {
+	switch {
+	case o.removeAllCustom:
+		return "remove every custom condition"
+	case o.typePattern != "":
+		return fmt.Sprintf("remove the conditions matching %s", o.typePattern)
+	case o.remove:
+		return fmt.Sprintf("remove condition %s", o.condition.Type)
+	case o.condition.Status == "":
+		return fmt.Sprintf("update condition %s", o.condition.Type)
+	default:
+		return fmt.Sprintf("set condition %s to %s", o.condition.Type, o.condition.Status)
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// newTerminal returns an openTerminal replacement answering the prompt with answer.
+func newTerminal(answer string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(answer)), nil
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	nodes := func(n int) []string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = "node-" + string(rune('a'+i))
+		}
+
+		return names
+	}
+
+	noTerminal := func() (io.ReadCloser, error) {
+		return nil, errors.New("no such device or address")
+	}
+
+	tests := []struct {
+		name           string
+		options        ConditionOptions
+		expectedErr    string
+		expectedStdErr string
+	}{
+		{
+			name:    "Success: below the threshold",
+			options: ConditionOptions{nodeNames: nodes(3), condition: &corev1.NodeCondition{Type: "Maintenance", Status: corev1.ConditionTrue}, changeOptions: changeOptions{config: &config.Config{}, openTerminal: noTerminal}},
+		},
+		{
+			name:    "Success: --yes skips the prompt",
+			options: ConditionOptions{nodeNames: nodes(3), remove: true, condition: &corev1.NodeCondition{Type: "Maintenance"}, changeOptions: changeOptions{config: &config.Config{}, yes: true, openTerminal: noTerminal}},
+		},
+		{
+			name:           "Success: confirmed removal",
+			options:        ConditionOptions{nodeNames: nodes(1), remove: true, condition: &corev1.NodeCondition{Type: "Maintenance"}, changeOptions: changeOptions{config: &config.Config{}, openTerminal: newTerminal("y\n")}},
+			expectedStdErr: "About to remove condition Maintenance on 1 node(s): node-a\nContinue? [y/N]: ",
+		},
+		{
+			name:           "Success: above a configured threshold with a sample",
+			options:        ConditionOptions{nodeNames: nodes(7), condition: &corev1.NodeCondition{Type: "Maintenance", Status: corev1.ConditionTrue}, changeOptions: changeOptions{config: &config.Config{ConfirmThreshold: 2}, openTerminal: newTerminal("YES")}},
+			expectedStdErr: "About to set condition Maintenance to True on 7 node(s): node-a, node-b, node-c, node-d, node-e and 2 more\nContinue? [y/N]: ",
+		},
+		{
+			name:           "Error: declined",
+			options:        ConditionOptions{nodeNames: nodes(2), remove: true, typePattern: "example.com/*", changeOptions: changeOptions{config: &config.Config{}, openTerminal: newTerminal("\n")}},
+			expectedErr:    "aborted, no changes were made",
+			expectedStdErr: "About to remove the conditions matching example.com/* on 2 node(s): node-a, node-b\nContinue? [y/N]: ",
+		},
+		{
+			name:        "Error: no terminal",
+			options:     ConditionOptions{nodeNames: nodes(1), remove: true, removeAllCustom: true, changeOptions: changeOptions{config: &config.Config{}, openTerminal: noTerminal}},
+			expectedErr: "confirmation is required to remove every custom condition on 1 node(s) but no terminal is available, use --yes to proceed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errOut := &bytes.Buffer{}
+			tt.options.IOStreams = genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: &bytes.Buffer{}, ErrOut: errOut}
+
+			err := tt.options.confirm(tt.options.describe(), tt.options.nodeNames, tt.options.remove)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedStdErr, errOut.String())
+		})
+	}
+}
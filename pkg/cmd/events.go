@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// reportingController is the reporting controller of the Events emitted for condition changes.
+const reportingController string = "conditioner.devbytes.cloud/conditioner"
+
+// changeAnnotation is the Event annotation recording the condition change in a structured form, so 'history' does not
+// have to parse the note. Its value is the JSON encoding of a conditionChange.
+const changeAnnotation string = "conditioner.devbytes.cloud/change"
+
+// conditionChange is a change of a condition as recorded on its Event.
+type conditionChange struct {
+	// Type is the condition type.
+	Type string `json:"type"`
+	// From is the status before the change, it is empty if the condition was added.
+	From string `json:"from,omitempty"`
+	// To is the status after the change, it is empty if the condition was removed.
+	To string `json:"to,omitempty"`
+	// Actor is the identity of the user making the change.
+	Actor string `json:"actor,omitempty"`
+}
+
+// Reasons of condition change Events whose condition has no reason of its own.
+const (
+	conditionChangedReason string = "ConditionChanged"
+	conditionRemovedReason string = "ConditionRemoved"
+)
+
+// emitConditionEvent creates an Event regarding the node recording the change of a condition from old to updated.
+// A nil old condition was added and a nil updated condition was removed. Nodes are cluster-scoped, so the Event
+// is created in the default namespace where kubectl looks for them. The reason is the reason of the updated
+// condition and the note describes the status change and the actor.
+func emitConditionEvent(client kubernetes.Interface, node *corev1.Node, old, updated *corev1.NodeCondition, actor string) error {
+	change := conditionChange{Actor: actor}
+	reason, action := conditionChangedReason, "AddCondition"
+
+	if old != nil {
+		change.Type = string(old.Type)
+		change.From = string(old.Status)
+		action = "ReplaceCondition"
+	}
+
+	if updated != nil {
+		change.Type = string(updated.Type)
+		change.To = string(updated.Status)
+		if updated.Reason != "" {
+			reason = updated.Reason
+		}
+	} else {
+		reason, action = conditionRemovedReason, "RemoveCondition"
+	}
+
+	annotation, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	note := fmt.Sprintf("condition %s changed from %s to %s", change.Type, statusOrNone(change.From), statusOrNone(change.To))
+	if actor != "" {
+		note += " by " + actor
+	}
+
+	if updated != nil && updated.Message != "" {
+		note += ": " + updated.Message
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s.%x", node.Name, now.UnixNano()),
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: map[string]string{changeAnnotation: string(annotation)},
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: reportingController,
+		ReportingInstance:   truncate(reportingController+"-"+host, 128),
+		Action:              action,
+		Reason:              truncate(reason, 128),
+		Regarding: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Node",
+			Name:       node.Name,
+			UID:        node.UID,
+		},
+		Note: truncate(note, 1024),
+		Type: corev1.EventTypeNormal,
+	}
+
+	_, err = client.EventsV1().Events(event.Namespace).Create(context.Background(), event, metav1.CreateOptions{})
+	return err
+}
+
+// statusOrNone returns the status, or <none> if the condition does not exist.
+func statusOrNone(status string) string {
+	if status == "" {
+		return "<none>"
+	}
+
+	return status
+}
+
+// truncate shortens s to at most n bytes, the API server rejects Events with longer fields.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return strings.ToValidUTF8(s[:n], "")
+}
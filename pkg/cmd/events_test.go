@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEmitConditionEvent(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-01", UID: "1234"}}
+
+	tests := []struct {
+		name       string
+		old        *corev1.NodeCondition
+		updated    *corev1.NodeCondition
+		actor      string
+		wantReason string
+		wantAction string
+		wantNote   string
+	}{
+		{
+			name:       "Added",
+			updated:    &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled", Message: "draining"},
+			actor:      "jane@example.com",
+			wantReason: "MaintenanceScheduled",
+			wantAction: "AddCondition",
+			wantNote:   "condition NodeMaintenance changed from <none> to True by jane@example.com: draining",
+		},
+		{
+			name:       "Replaced without reason",
+			old:        &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue},
+			updated:    &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionFalse},
+			wantReason: conditionChangedReason,
+			wantAction: "ReplaceCondition",
+			wantNote:   "condition NodeMaintenance changed from True to False",
+		},
+		{
+			name:       "Removed",
+			old:        &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionFalse, Reason: "MaintenanceComplete"},
+			actor:      "jane@example.com",
+			wantReason: conditionRemovedReason,
+			wantAction: "RemoveCondition",
+			wantNote:   "condition NodeMaintenance changed from False to <none> by jane@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientset()
+			require.NoError(t, emitConditionEvent(client, node, tt.old, tt.updated, tt.actor))
+
+			events, err := client.EventsV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, events.Items, 1)
+
+			event := events.Items[0]
+			assert.True(t, strings.HasPrefix(event.Name, "worker-01."))
+			assert.Equal(t, corev1.ObjectReference{APIVersion: "v1", Kind: "Node", Name: "worker-01", UID: "1234"}, event.Regarding)
+			assert.Equal(t, tt.wantReason, event.Reason)
+			assert.Equal(t, tt.wantAction, event.Action)
+			assert.Equal(t, tt.wantNote, event.Note)
+			assert.Equal(t, corev1.EventTypeNormal, event.Type)
+			assert.Equal(t, reportingController, event.ReportingController)
+			assert.Contains(t, event.Annotations, changeAnnotation)
+		})
+	}
+}
+
+func TestRunForNodeEvents(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-01"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady},
+			{Type: "team-a.example.com/Healthy", Status: corev1.ConditionTrue},
+			{Type: "team-a.example.com/Ready", Status: corev1.ConditionTrue},
+		}},
+	}
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewConditionOptions(streams)
+	o.client = fake.NewClientset(node)
+	o.config = &config.Config{}
+	o.events = true
+	o.actor = "jane@example.com"
+	o.condition = &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled"}
+
+	require.NoError(t, o.runForNode("worker-01"))
+
+	o.remove = true
+	o.typePattern = "team-a.example.com/*"
+	require.NoError(t, o.runForNode("worker-01"))
+
+	events, err := o.client.EventsV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+
+	var notes []string
+	for _, event := range events.Items {
+		notes = append(notes, event.Note)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"condition NodeMaintenance changed from <none> to True by jane@example.com",
+		"condition team-a.example.com/Healthy changed from True to <none> by jane@example.com",
+		"condition team-a.example.com/Ready changed from True to <none> by jane@example.com",
+	}, notes)
+}
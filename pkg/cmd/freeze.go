@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkFreeze refuses to change conditions of the condition types while a change freeze covers them, unless
+// --override-freeze is provided with a justification. Overriding a freeze requires an audit log, as the justification
+// is recorded with every change in the audit log. An empty condition type stands for a change that is not limited
+// to a single condition type, such as removing conditions by pattern, which is covered by every freeze of the
+// kubeconfig context.
+func (o *changeOptions) checkFreeze(conditionTypes ...string) error {
+	if len(o.config.Freezes) == 0 || len(conditionTypes) == 0 {
+		return nil
+	}
+
+	context, _, err := kubeContext(o.configFlags)
+	if err != nil {
+		return err
+	}
+
+	for _, conditionType := range conditionTypes {
+		freeze, until, err := o.config.ActiveFreeze(o.now(), context, conditionType)
+		if err != nil {
+			return err
+		}
+
+		if freeze == nil {
+			continue
+		}
+
+		if !o.overrideFreeze {
+			return fmt.Errorf("change freeze %s is in effect until %s, use --override-freeze with a --justification to change conditions anyway", freeze, until.Format(time.RFC3339))
+		}
+
+		if o.auditLog == nil {
+			return fmt.Errorf("overriding change freeze %s requires an audit-log, so the justification is recorded", freeze)
+		}
+
+		fmt.Fprintf(o.ErrOut, "warning: overriding change freeze %s: %s\n", freeze, o.justification)
+
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/audit"
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newFreezeConfig returns a configuration freezing NodeMaintenance in the dev context over the end of 2026.
+func newFreezeConfig() *config.Config {
+	return &config.Config{
+		Freezes: []config.Freeze{
+			{Name: "end-of-year", Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z", Types: []string{"NodeMaintenance"}, Contexts: []string{"dev"}},
+		},
+	}
+}
+
+func TestCheckFreeze(t *testing.T) {
+	tests := []struct {
+		name           string
+		conditionTypes []string
+		override       bool
+		auditLog       bool
+		now            time.Time
+		expectedErr    string
+		expectedStdErr string
+	}{
+		{
+			name:           "Success: outside of the freeze",
+			conditionTypes: []string{"NodeMaintenance"},
+			now:            time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "Success: condition type not covered",
+			conditionTypes: []string{"UnderInvestigation"},
+			now:            time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Success: no condition types",
+			now:  time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "Success: freeze overridden",
+			conditionTypes: []string{"NodeMaintenance"},
+			override:       true,
+			auditLog:       true,
+			now:            time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+			expectedStdErr: "warning: overriding change freeze end-of-year: INC-1234 hardware replacement\n",
+		},
+		{
+			name:           "Error: frozen",
+			conditionTypes: []string{"UnderInvestigation", "NodeMaintenance"},
+			now:            time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+			expectedErr:    "change freeze end-of-year is in effect until 2027-01-04T00:00:00Z, use --override-freeze with a --justification to change conditions anyway",
+		},
+		{
+			name:           "Error: pattern removal is covered by every freeze",
+			conditionTypes: []string{""},
+			now:            time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+			expectedErr:    "change freeze end-of-year is in effect until 2027-01-04T00:00:00Z, use --override-freeze with a --justification to change conditions anyway",
+		},
+		{
+			name:           "Error: override without an audit log",
+			conditionTypes: []string{"NodeMaintenance"},
+			override:       true,
+			now:            time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC),
+			expectedErr:    "overriding change freeze end-of-year requires an audit-log, so the justification is recorded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+			o := newChangeOptions(streams)
+			o.configFlags = newTestConfigFlags(t)
+			o.config = newFreezeConfig()
+			o.now = func() time.Time { return tt.now }
+			o.overrideFreeze = tt.override
+			o.justification = "INC-1234 hardware replacement"
+
+			if tt.auditLog {
+				o.auditLog = audit.New(filepath.Join(t.TempDir(), "audit.log"), 1024*1024, 1)
+			}
+
+			err := o.checkFreeze(tt.conditionTypes...)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedStdErr, errOut.String())
+		})
+	}
+}
+
+func TestCompleteFreeze(t *testing.T) {
+	t.Run("Error: override without justification", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true", "override-freeze": "true", "justification": "  "})
+
+		err := o.Complete(c, nil, &config.Config{})
+		assert.EqualError(t, err, "--override-freeze requires a --justification")
+	})
+
+	t.Run("Error: frozen", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		o.now = func() time.Time { return time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC) }
+		c := newCompleteCommand(t, map[string]string{"type": "NodeMaintenance", "status": "true"})
+
+		err := o.Complete(c, nil, newFreezeConfig())
+		assert.EqualError(t, err, "change freeze end-of-year is in effect until 2027-01-04T00:00:00Z, use --override-freeze with a --justification to change conditions anyway")
+	})
+}
+
+func TestReapFreeze(t *testing.T) {
+	now := time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC)
+
+	node := newProbedNode("worker-01", now)
+	node.Status.Conditions[1].Type = "NodeMaintenance"
+
+	o := NewReapOptions(genericiooptions.IOStreams{})
+	o.client = fake.NewClientset(node)
+	o.configFlags = newTestConfigFlags(t)
+	o.config = newFreezeConfig()
+	o.now = func() time.Time { return now }
+	o.conditionType = "*"
+	o.maxHeartbeatAge = 10 * time.Minute
+	o.status = corev1.ConditionUnknown
+
+	err := o.Run()
+	assert.EqualError(t, err, "change freeze end-of-year is in effect until 2027-01-04T00:00:00Z, use --override-freeze with a --justification to change conditions anyway")
+}
+
+func TestPruneFreeze(t *testing.T) {
+	now := time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC)
+
+	node := newExpiringNode("worker-01", now)
+	node.Annotations[expiryAnnotation] = `{"NodeMaintenance":"` + now.Add(-time.Minute).Format(time.RFC3339) + `"}`
+
+	o := NewPruneOptions(genericiooptions.IOStreams{})
+	o.client = fake.NewClientset(node)
+	o.configFlags = newTestConfigFlags(t)
+	o.config = newFreezeConfig()
+	o.now = func() time.Time { return now }
+
+	err := o.Run()
+	assert.EqualError(t, err, "change freeze end-of-year is in effect until 2027-01-04T00:00:00Z, use --override-freeze with a --justification to change conditions anyway")
+}
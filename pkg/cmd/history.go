@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	historyExample = `
+# Show every condition change conditioner made on a node
+kubectl conditioner history my-node
+
+# Show the changes of a single condition type, or of the condition types matching a glob
+kubectl conditioner history my-node --type NodeMaintenance
+kubectl conditioner history my-node --type 'team-a.example.com/*'
+`
+
+	historyLong = `The 'history' command reconstructs the timeline of the conditions of a node from the Events conditioner
+emits when 'events' is enabled and the author annotation it writes when 'record-author' is enabled.
+Events are only kept by the API server for a limited time (one hour by default), while the author annotation only holds
+the latest change of each condition type, so older changes may be missing.
+The '--type' flag may be a glob or a regular expression wrapped in slashes.`
+)
+
+// historySourceEvent and historySourceAnnotation are the sources a history entry was reconstructed from.
+const (
+	historySourceEvent      string = "event"
+	historySourceAnnotation string = "annotation"
+)
+
+// historyEntry is a single change in the timeline of a condition.
+type historyEntry struct {
+	// time is when the change was made.
+	time time.Time
+	// conditionType is the condition type.
+	conditionType string
+	// from and to are the statuses before and after the change, empty if the condition did not exist.
+	from, to string
+	// actor is who made the change.
+	actor string
+	// reason is the reason of the change.
+	reason string
+	// source is where the entry was reconstructed from.
+	source string
+}
+
+// HistoryOptions is a struct that holds the configuration for the history command.
+type HistoryOptions struct {
+	// client is used to interact with the Kubernetes API.
+	client kubernetes.Interface
+
+	// configFlags holds the configuration flags for the command.
+	configFlags *genericclioptions.ConfigFlags
+
+	// IOStreams provides the standard names for iostreams. This is useful for embedding and for unit testing.
+	genericiooptions.IOStreams
+
+	// nodeName is the name of the node whose history is shown.
+	nodeName string
+
+	// conditionType is the condition type, glob or regular expression selecting the conditions shown.
+	// Every condition is shown when it is empty.
+	conditionType string
+}
+
+// NewHistoryOptions is a function that creates a new HistoryOptions.
+func NewHistoryOptions(streams genericiooptions.IOStreams) *HistoryOptions {
+	return &HistoryOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+	}
+}
+
+// NewCmdHistory returns a cobra.Command that implements the history subcommand.
+func NewCmdHistory(streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewHistoryOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "history <node name> [flags]",
+		Short:        "Show the history of the conditions of a node.",
+		Long:         historyLong,
+		Example:      historyExample,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringP("type", "", "", "Type of condition to show the history of, may be a glob or /regex/")
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Complete creates the Kubernetes client and validates the flags.
+func (o *HistoryOptions) Complete(cmd *cobra.Command, args []string) error {
+	restConfig, err := o.configFlags.ToRawKubeConfigLoader().ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	o.nodeName = normalizeNodeName(args[0])
+	if o.nodeName == "" {
+		return fmt.Errorf("node name cannot be empty")
+	}
+
+	o.conditionType, err = cmd.Flags().GetString("type")
+	if err != nil {
+		return err
+	}
+
+	if o.conditionType != "" {
+		return pattern.Validate(o.conditionType)
+	}
+
+	return nil
+}
+
+// Run prints the timeline of the conditions of the node, oldest change first.
+func (o *HistoryOptions) Run() error {
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), o.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	entries, err := o.eventEntries(node)
+	if err != nil {
+		return err
+	}
+
+	annotationEntries, err := annotationEntries(node, entries)
+	if err != nil {
+		return err
+	}
+
+	var selected []historyEntry
+	for _, entry := range append(entries, annotationEntries...) {
+		ok, err := o.matches(entry.conditionType)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			selected = append(selected, entry)
+		}
+	}
+
+	if len(selected) == 0 {
+		fmt.Fprintf(o.Out, "no condition history found for node %s\n", node.Name)
+		return nil
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].time.Before(selected[j].time)
+	})
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTYPE\tCHANGE\tBY\tREASON\tSOURCE")
+	for _, entry := range selected {
+		fmt.Fprintf(w, "%s\t%s\t%s -> %s\t%s\t%s\t%s\n",
+			entry.time.UTC().Format(time.RFC3339), entry.conditionType, statusOrNone(entry.from), statusOrNone(entry.to),
+			valueOrUnknown(entry.actor), valueOrUnknown(entry.reason), entry.source)
+	}
+
+	return w.Flush()
+}
+
+// matches reports whether the condition type is selected by --type.
+func (o *HistoryOptions) matches(conditionType string) (bool, error) {
+	if o.conditionType == "" {
+		return true, nil
+	}
+
+	return pattern.Match(o.conditionType, conditionType)
+}
+
+// eventEntries returns the history entries of the Events conditioner emitted for the node.
+func (o *HistoryOptions) eventEntries(node *corev1.Node) ([]historyEntry, error) {
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("regarding.kind", "Node"),
+		fields.OneTermEqualSelector("regarding.name", node.Name),
+	)
+
+	events, err := o.client.EventsV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("listing events: %w", err)
+	}
+
+	var entries []historyEntry
+	for _, event := range events.Items {
+		value, ok := event.Annotations[changeAnnotation]
+		if !ok || event.ReportingController != reportingController || event.Regarding.Name != node.Name {
+			continue
+		}
+
+		var change conditionChange
+		if err := json.Unmarshal([]byte(value), &change); err != nil {
+			return nil, fmt.Errorf("decoding annotation %s of event %s: %w", changeAnnotation, event.Name, err)
+		}
+
+		entries = append(entries, historyEntry{
+			time:          event.EventTime.Time,
+			conditionType: change.Type,
+			from:          change.From,
+			to:            change.To,
+			actor:         change.Actor,
+			reason:        event.Reason,
+			source:        historySourceEvent,
+		})
+	}
+
+	return entries, nil
+}
+
+// annotationEntries returns the history entries of the author annotation of the node that are not already
+// covered by an Event. The annotation only records the latest change of each condition type, so the status
+// after the change is the current status and the status before it is unknown.
+func annotationEntries(node *corev1.Node, eventEntries []historyEntry) ([]historyEntry, error) {
+	authors, err := readAuthors(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []historyEntry
+	for conditionType, record := range authors {
+		if coveredByEvent(eventEntries, conditionType, record.When) {
+			continue
+		}
+
+		entry := historyEntry{
+			time:          record.When,
+			conditionType: conditionType,
+			from:          "?",
+			actor:         record.Who,
+			source:        historySourceAnnotation,
+		}
+
+		if record.Operation == "add" {
+			entry.from = ""
+		}
+
+		if condition, _ := findConditionType(node.Status.Conditions, corev1.NodeConditionType(conditionType)); condition != nil && record.Operation != "remove" {
+			entry.to = string(condition.Status)
+			entry.reason = condition.Reason
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// coveredByEvent reports whether an Event records the change of the condition type made at when.
+// The author annotation is truncated to the second, so Events within a second of it match.
+func coveredByEvent(eventEntries []historyEntry, conditionType string, when time.Time) bool {
+	for _, entry := range eventEntries {
+		if entry.conditionType == conditionType && entry.time.Sub(when).Abs() < 2*time.Second {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueOrUnknown returns the value, or <unknown> if it is empty.
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "<unknown>"
+	}
+
+	return value
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHistoryRun(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "worker-01",
+			Annotations: map[string]string{
+				authorAnnotation: `{"NodeMaintenance":{"who":"jane@example.com","when":"2024-09-01T07:00:00Z","op":"replace"},` +
+					`"UnderInvestigation":{"who":"john@example.com","when":"2024-09-01T06:00:00Z","op":"add"}}`,
+			},
+		},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: "NodeMaintenance", Status: corev1.ConditionFalse, Reason: "MaintenanceComplete"},
+			{Type: "UnderInvestigation", Status: corev1.ConditionTrue, Reason: "PagerAlert"},
+		}},
+	}
+
+	client := fake.NewClientset(node)
+	other := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-02"}}
+	require.NoError(t, emitConditionEvent(client, node, nil, &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled"}, "jane@example.com"))
+	require.NoError(t, emitConditionEvent(client, other, nil, &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue}, "jane@example.com"))
+
+	t.Run("Every condition", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		o := NewHistoryOptions(streams)
+		o.client = client
+		o.nodeName = "worker-01"
+
+		require.NoError(t, o.Run())
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, "TIME                  TYPE                CHANGE          BY                REASON                SOURCE", lines[0])
+		assert.Equal(t, "2024-09-01T06:00:00Z  UnderInvestigation  <none> -> True  john@example.com  PagerAlert            annotation", lines[1])
+		assert.Equal(t, "2024-09-01T07:00:00Z  NodeMaintenance     ? -> False      jane@example.com  MaintenanceComplete   annotation", lines[2])
+
+		// The event was emitted now, after both annotation records.
+		assert.True(t, strings.HasSuffix(lines[3], "  NodeMaintenance     <none> -> True  jane@example.com  MaintenanceScheduled  event"), lines[3])
+	})
+
+	t.Run("Filtered by type", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		o := NewHistoryOptions(streams)
+		o.client = client
+		o.nodeName = "worker-01"
+		o.conditionType = "Under*"
+
+		require.NoError(t, o.Run())
+		assert.Contains(t, out.String(), "UnderInvestigation")
+		assert.NotContains(t, out.String(), "NodeMaintenance")
+	})
+
+	t.Run("No history", func(t *testing.T) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		o := NewHistoryOptions(streams)
+		o.client = client
+		o.nodeName = "worker-01"
+		o.conditionType = "Healthy"
+
+		require.NoError(t, o.Run())
+		assert.Equal(t, "no condition history found for node worker-01\n", out.String())
+	})
+}
+
+func TestAnnotationEntriesCoveredByEvent(t *testing.T) {
+	when := time.Date(2024, 9, 1, 7, 0, 0, 0, time.UTC)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker-01",
+			Annotations: map[string]string{authorAnnotation: `{"NodeMaintenance":{"who":"jane@example.com","when":"2024-09-01T07:00:00Z","op":"remove"}}`},
+		},
+	}
+
+	entries, err := annotationEntries(node, []historyEntry{{time: when.Add(500 * time.Millisecond), conditionType: "NodeMaintenance"}})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = annotationEntries(node, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "?", entries[0].from)
+	assert.Empty(t, entries[0].to)
+}
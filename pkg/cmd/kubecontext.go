@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// kubeContext returns the names of the kubeconfig context and cluster the command runs against.
+// The --context and --cluster flags take precedence over the current context of the kubeconfig.
+func kubeContext(configFlags *genericclioptions.ConfigFlags) (string, string, error) {
+	rawConfig, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	contextName := rawConfig.CurrentContext
+	if configFlags.Context != nil && *configFlags.Context != "" {
+		contextName = *configFlags.Context
+	}
+
+	clusterName := ""
+	if kubeContext, ok := rawConfig.Contexts[contextName]; ok {
+		clusterName = kubeContext.Cluster
+	}
+
+	if configFlags.ClusterName != nil && *configFlags.ClusterName != "" {
+		clusterName = *configFlags.ClusterName
+	}
+
+	return contextName, clusterName, nil
+}
+
+// profileConfig returns the configuration with the profile of the kubeconfig context the command runs against applied.
+func profileConfig(configFlags *genericclioptions.ConfigFlags, conf *config.Config) (*config.Config, error) {
+	contextName, clusterName, err := kubeContext(configFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	return conf.ForContext(contextName, clusterName), nil
+}
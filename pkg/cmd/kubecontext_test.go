@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+- name: prod
+  context:
+    cluster: prod-cluster
+`
+
+func newTestConfigFlags(t *testing.T) *genericclioptions.ConfigFlags {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(testKubeconfig), 0o600))
+
+	configFlags := genericclioptions.NewConfigFlags(true)
+	configFlags.KubeConfig = &path
+
+	return configFlags
+}
+
+func TestKubeContext(t *testing.T) {
+	t.Run("Current context", func(t *testing.T) {
+		contextName, clusterName, err := kubeContext(newTestConfigFlags(t))
+		require.NoError(t, err)
+		assert.Equal(t, "dev", contextName)
+		assert.Equal(t, "dev-cluster", clusterName)
+	})
+
+	t.Run("Context flag", func(t *testing.T) {
+		configFlags := newTestConfigFlags(t)
+		contextName := "prod"
+		configFlags.Context = &contextName
+
+		contextName, clusterName, err := kubeContext(configFlags)
+		require.NoError(t, err)
+		assert.Equal(t, "prod", contextName)
+		assert.Equal(t, "prod-cluster", clusterName)
+	})
+
+	t.Run("Cluster flag", func(t *testing.T) {
+		configFlags := newTestConfigFlags(t)
+		clusterName := "prod-cluster"
+		configFlags.ClusterName = &clusterName
+
+		contextName, clusterName, err := kubeContext(configFlags)
+		require.NoError(t, err)
+		assert.Equal(t, "dev", contextName)
+		assert.Equal(t, "prod-cluster", clusterName)
+	})
+}
+
+func TestCompleteProfile(t *testing.T) {
+	conf := &config.Config{
+		Profiles: map[string]config.Profile{
+			"prod-cluster": {AllowList: []string{"NodeMaintenance"}},
+		},
+	}
+
+	t.Run("Success: no profile for the current context", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		c := newCompleteCommand(t, map[string]string{"type": "UnderInvestigation", "status": "true"})
+
+		assert.NoError(t, o.Complete(c, nil, conf))
+	})
+
+	t.Run("Error: profile of the selected context applies", func(t *testing.T) {
+		o := newCompleteOptions(t, genericiooptions.IOStreams{})
+		contextName := "prod"
+		o.configFlags.Context = &contextName
+		c := newCompleteCommand(t, map[string]string{"type": "UnderInvestigation", "status": "true"})
+
+		err := o.Complete(c, nil, conf)
+		assert.EqualError(t, err, "condition UnderInvestigation is not in allow-list [NodeMaintenance]")
+	})
+}
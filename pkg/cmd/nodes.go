@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// collectNodeNames merges the node names piped in on stdin with the positional arguments
+// and normalizes them. Unlike setNodeNames it allows no names at all, which subcommands
+// that scan the whole cluster treat as "every node".
+func collectNodeNames(in io.Reader, args []string) ([]string, error) {
+	stdinNames, err := readStdinNames(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeNames []string
+	for _, rawName := range append(stdinNames, args...) {
+		nodeName := normalizeNodeName(rawName)
+		if nodeName == "" {
+			return nil, fmt.Errorf("node name cannot be empty")
+		}
+
+		nodeNames = append(nodeNames, nodeName)
+	}
+
+	return nodeNames, nil
+}
+
+// listNodes returns the named nodes, or every node in the cluster when no names are given.
+func listNodes(client kubernetes.Interface, nodeNames []string) ([]corev1.Node, error) {
+	if len(nodeNames) == 0 {
+		list, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return list.Items, nil
+	}
+
+	nodes := make([]corev1.Node, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		node, err := client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", nodeName, err)
+		}
+
+		nodes = append(nodes, *node)
+	}
+
+	return nodes, nil
+}
+
+// patchStatus fetches the node, builds the JSON Patch of its status with build and applies it. The patches address
+// conditions by index and guard them with test operations, so when the conditions change between the Get and the
+// Patch the API server rejects the patch, and the node is fetched again and the patch rebuilt from it.
+// The returned node is the one the applied patch was built from. It is nil when the node could not be fetched or
+// build failed, as nothing was attempted on the node. build returns no operations when the node needs no change.
+func patchStatus(client kubernetes.Interface, nodeName string, build func(node *corev1.Node) ([]interface{}, error)) (*corev1.Node, error) {
+	var node *corev1.Node
+	err := retry.OnError(retry.DefaultRetry, isPatchConflict, func() error {
+		var err error
+		node, err = client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			node = nil
+			return err
+		}
+
+		operations, err := build(node)
+		if err != nil {
+			node = nil
+			return err
+		}
+
+		if len(operations) == 0 {
+			return nil
+		}
+
+		bytePatch, err := json.Marshal(operations)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.CoreV1().Nodes().Patch(context.Background(), node.Name, types.JSONPatchType, bytePatch, metav1.PatchOptions{}, "status")
+		return err
+	})
+
+	return node, err
+}
+
+// isPatchConflict reports whether the patch was rejected because the node changed since it was read.
+// A failing JSON Patch test operation is reported as Invalid, a changed resourceVersion as Conflict.
+func isPatchConflict(err error) bool {
+	return apierrors.IsInvalid(err) || apierrors.IsConflict(err)
+}
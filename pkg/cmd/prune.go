@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/devbytes-cloud/conditioner/pkg/jsonpatch"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+var (
+	pruneExample = `
+# Remove every expired condition from all nodes
+kubectl conditioner prune
+
+# Flip expired conditions on specific nodes to Unknown instead of removing them
+kubectl conditioner prune my-node other-node --set-unknown
+`
+
+	pruneLong = `The 'prune' command expires conditions that were set with '--ttl'. 
+Without node names every node in the cluster is scanned, node names can also be piped in on stdin. 
+Expired conditions are removed, or set to 'Unknown' with the reason 'ConditionExpired' when '--set-unknown' is provided.`
+)
+
+// expiredReason is the reason set on conditions flipped to Unknown by prune.
+const expiredReason string = "ConditionExpired"
+
+// PruneOptions is a struct that holds the configuration for the prune command.
+type PruneOptions struct {
+	// changeOptions holds the client, the configuration and the recording of the changes shared with the
+	// conditioner command and reap.
+	changeOptions
+
+	// nodeNames are the names of the nodes to prune. When empty every node is pruned.
+	nodeNames []string
+
+	// setUnknown indicates whether expired conditions are set to Unknown instead of being removed.
+	setUnknown bool
+}
+
+// NewPruneOptions is a function that creates a new PruneOptions.
+func NewPruneOptions(streams genericiooptions.IOStreams) *PruneOptions {
+	return &PruneOptions{
+		changeOptions: newChangeOptions(streams),
+	}
+}
+
+// NewCmdPrune returns a cobra.Command that implements the prune subcommand.
+func NewCmdPrune(streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewPruneOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "prune [node name ...] [flags]",
+		Short:        "Expire conditions that were set with a TTL.",
+		Long:         pruneLong,
+		Example:      pruneExample,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			conf, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+
+			if err := o.Complete(c, args, conf); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolP("set-unknown", "", false, "Set expired conditions to Unknown instead of removing them")
+
+	addChangeFlags(cmd)
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Complete creates the Kubernetes client and sets up the recording of the changes with completeChange, collects
+// the node names from args and stdin and checks the user is authorized to prune them.
+func (o *PruneOptions) Complete(cmd *cobra.Command, args []string, config *config.Config) error {
+	if err := o.completeChange(cmd, config); err != nil {
+		return err
+	}
+
+	var err error
+	o.setUnknown, err = cmd.Flags().GetBool("set-unknown")
+	if err != nil {
+		return err
+	}
+
+	o.nodeNames, err = collectNodeNames(o.In, args)
+	if err != nil {
+		return err
+	}
+
+	return o.preflight(readNodesPermission(o.nodeNames))
+}
+
+// Run prunes the expired conditions of the selected nodes.
+func (o *PruneOptions) Run() error {
+	return o.changeNodes(o.nodeNames, o.describe(), !o.setUnknown, "", o.expiredTypes, o.pruneNode)
+}
+
+// describe describes the change prune makes for the confirmation prompt.
+func (o *PruneOptions) describe() string {
+	if o.setUnknown {
+		return "set the expired conditions to Unknown"
+	}
+
+	return "remove the expired conditions"
+}
+
+// expiredTypes returns the condition types whose expiry recorded on the node has passed.
+func (o *PruneOptions) expiredTypes(node *corev1.Node) ([]string, error) {
+	expiries, err := readExpiries(node)
+	if err != nil {
+		return nil, err
+	}
+
+	now := o.now()
+
+	var conditionTypes []string
+	for conditionType, expiry := range expiries {
+		if !expiry.After(now) {
+			conditionTypes = append(conditionTypes, conditionType)
+		}
+	}
+
+	return conditionTypes, nil
+}
+
+// pruneNode removes, or sets to Unknown, every expired condition of the node and drops the expired
+// entries from the expiry annotation in a single JSON Patch request, and records the changes.
+func (o *PruneOptions) pruneNode(nodeName string) error {
+	var indices []int
+	var updates map[int]*corev1.NodeCondition
+	node, err := patchStatus(o.client, nodeName, func(node *corev1.Node) ([]interface{}, error) {
+		expiries, err := readExpiries(node)
+		if err != nil {
+			return nil, err
+		}
+
+		now := o.now()
+		expired := make(map[corev1.NodeConditionType]*time.Time)
+		indices = nil
+		updates = map[int]*corev1.NodeCondition{}
+		for conditionType, expiry := range expiries {
+			if expiry.After(now) {
+				continue
+			}
+
+			expired[corev1.NodeConditionType(conditionType)] = nil
+			if _, index := findConditionType(node.Status.Conditions, corev1.NodeConditionType(conditionType)); index != -1 {
+				indices = append(indices, index)
+			}
+		}
+
+		if len(expired) == 0 {
+			return nil, nil
+		}
+
+		sort.Ints(indices)
+
+		operations := make(map[corev1.NodeConditionType]string, len(indices))
+
+		var jsonPath []interface{}
+		if o.setUnknown {
+			for _, index := range indices {
+				old := node.Status.Conditions[index]
+				patch := jsonpatch.GenerateJsonPath(index, false, &old, &corev1.NodeCondition{
+					Type:    old.Type,
+					Status:  corev1.ConditionUnknown,
+					Reason:  expiredReason,
+					Message: fmt.Sprintf("condition expired at %s", expiries[string(old.Type)].Format(time.RFC3339)),
+				})
+
+				updates[index] = patch.Value
+				operations[old.Type] = patch.OP
+				jsonPath = append(jsonPath, jsonpatch.GenerateTestPatch(index, old.Type), patch)
+			}
+		} else {
+			for _, index := range indices {
+				operations[node.Status.Conditions[index].Type] = "remove"
+			}
+
+			jsonPath = jsonpatch.GenerateRemovePatches(node.Status.Conditions, indices)
+		}
+
+		annotations := map[string]*string{}
+		if err := updateExpiries(node, expired, annotations); err != nil {
+			return nil, err
+		}
+
+		if err := o.recordAuthor(node, operations, annotations); err != nil {
+			return nil, err
+		}
+
+		return append(jsonPath, annotationPatches(node, annotations)...), nil
+	})
+	if node == nil {
+		return err
+	}
+
+	operation := "remove"
+	if o.setUnknown {
+		operation = "replace"
+	}
+
+	for _, index := range indices {
+		o.recordAudit(node.Name, string(node.Status.Conditions[index].Type), operation, &node.Status.Conditions[index], updates[index], err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		action := "removed"
+		if o.setUnknown {
+			action = "set to Unknown"
+		}
+
+		fmt.Fprintf(o.Out, "expired condition status %s has been %s on node %s\n", node.Status.Conditions[index].Type, action, node.Name)
+		o.emitEvent(node, &node.Status.Conditions[index], updates[index])
+	}
+
+	return nil
+}
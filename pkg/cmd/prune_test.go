@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/audit"
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newExpiringNode returns a node with two conditions, one of which has expired at now.
+func newExpiringNode(name string, now time.Time) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				expiryAnnotation: `{"UnderInvestigation":"` + now.Add(-time.Minute).Format(time.RFC3339) + `","Draining":"` + now.Add(time.Hour).Format(time.RFC3339) + `"}`,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: "UnderInvestigation", Status: corev1.ConditionTrue},
+				{Type: "Draining", Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestPruneRemovesExpiredConditions(t *testing.T) {
+	now := time.Now()
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewPruneOptions(streams)
+	o.config = &config.Config{}
+	o.client = fake.NewClientset(newExpiringNode("worker-01", now), newExpiringNode("worker-02", now))
+	o.now = func() time.Time { return now }
+	o.yes = true
+
+	require.NoError(t, o.Run())
+
+	for _, nodeName := range []string{"worker-01", "worker-02"} {
+		node, err := o.client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		_, index := findConditionType(node.Status.Conditions, "UnderInvestigation")
+		assert.Equal(t, -1, index)
+		_, index = findConditionType(node.Status.Conditions, "Draining")
+		assert.NotEqual(t, -1, index)
+
+		expiries, err := readExpiries(node)
+		require.NoError(t, err)
+		assert.NotContains(t, expiries, "UnderInvestigation")
+		assert.Contains(t, expiries, "Draining")
+	}
+
+	assert.Contains(t, out.String(), "expired condition status UnderInvestigation has been removed on node worker-01")
+}
+
+func TestPruneSetUnknown(t *testing.T) {
+	now := time.Now()
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewPruneOptions(streams)
+	o.config = &config.Config{}
+	o.client = fake.NewClientset(newExpiringNode("worker-01", now))
+	o.now = func() time.Time { return now }
+	o.nodeNames = []string{"worker-01"}
+	o.setUnknown = true
+
+	require.NoError(t, o.Run())
+
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	condition, index := findConditionType(node.Status.Conditions, "UnderInvestigation")
+	require.NotEqual(t, -1, index)
+	assert.Equal(t, corev1.ConditionUnknown, condition.Status)
+	assert.Equal(t, expiredReason, condition.Reason)
+}
+
+func TestPruneDropsLastExpiry(t *testing.T) {
+	now := time.Now()
+	node := newExpiringNode("worker-01", now)
+	node.Annotations[expiryAnnotation] = `{"Gone":"` + now.Add(-time.Minute).Format(time.RFC3339) + `"}`
+
+	o := NewPruneOptions(genericiooptions.IOStreams{})
+	o.config = &config.Config{}
+	o.client = fake.NewClientset(node)
+	o.now = func() time.Time { return now }
+	o.yes = true
+
+	require.NoError(t, o.Run())
+
+	got, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, got.Annotations, expiryAnnotation)
+	assert.Len(t, got.Status.Conditions, 3)
+}
+
+func TestPruneRecordsChanges(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewPruneOptions(streams)
+	o.config = &config.Config{}
+	o.client = fake.NewClientset(newExpiringNode("worker-01", now))
+	o.now = func() time.Time { return now }
+	o.yes = true
+	o.auditLog = audit.New(path, 1024*1024, 1)
+	o.events = true
+	o.actor = "jane@example.com"
+
+	require.NoError(t, o.Run())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "worker-01", entry.Node)
+	assert.Equal(t, "UnderInvestigation", entry.ConditionType)
+	assert.Equal(t, "remove", entry.Operation)
+	assert.Equal(t, "jane@example.com", entry.Actor)
+	assert.Equal(t, audit.ResultSuccess, entry.Result)
+	assert.True(t, entry.Timestamp.Equal(now), "the entry is timestamped with o.now")
+
+	events, err := o.client.EventsV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, "condition UnderInvestigation changed from True to <none> by jane@example.com", events.Items[0].Note)
+}
+
+func TestPruneLimitsAndConfirmation(t *testing.T) {
+	now := time.Now()
+	newOptions := func() *PruneOptions {
+		o := NewPruneOptions(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+		o.config = &config.Config{ConfirmThreshold: 1}
+		o.client = fake.NewClientset(newExpiringNode("worker-01", now), newExpiringNode("worker-02", now), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-03"}})
+		o.now = func() time.Time { return now }
+		o.yes = true
+
+		return o
+	}
+
+	assertUnchanged := func(t *testing.T, o *PruneOptions) {
+		node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		_, index := findConditionType(node.Status.Conditions, "UnderInvestigation")
+		assert.NotEqual(t, -1, index)
+	}
+
+	t.Run("Success: only nodes with expired conditions count against the limit", func(t *testing.T) {
+		o := newOptions()
+		o.maxNodes = 2
+
+		require.NoError(t, o.Run())
+	})
+
+	t.Run("Success: confirmed removal", func(t *testing.T) {
+		o := newOptions()
+		o.config = &config.Config{}
+		o.yes = false
+		o.openTerminal = newTerminal("y\n")
+
+		require.NoError(t, o.Run())
+		assert.Equal(t, "About to remove the expired conditions on 2 node(s): worker-01, worker-02\nContinue? [y/N]: ", o.ErrOut.(*bytes.Buffer).String())
+	})
+
+	t.Run("Success: setting Unknown below the threshold is not confirmed", func(t *testing.T) {
+		o := newOptions()
+		o.config = &config.Config{}
+		o.yes = false
+		o.setUnknown = true
+		o.openTerminal = newTerminal("n\n")
+
+		require.NoError(t, o.Run())
+		assert.Empty(t, o.ErrOut.(*bytes.Buffer).String())
+	})
+
+	t.Run("Success: setting Unknown above the threshold is confirmed", func(t *testing.T) {
+		o := newOptions()
+		o.yes = false
+		o.setUnknown = true
+		o.openTerminal = newTerminal("y\n")
+
+		require.NoError(t, o.Run())
+		assert.Equal(t, "About to set the expired conditions to Unknown on 2 node(s): worker-01, worker-02\nContinue? [y/N]: ", o.ErrOut.(*bytes.Buffer).String())
+	})
+
+	t.Run("Error: more than max-nodes", func(t *testing.T) {
+		o := newOptions()
+		o.maxNodes = 1
+
+		assert.EqualError(t, o.Run(), "refusing to change 2 nodes, the limit is 1 nodes, use --max-nodes to raise it")
+		assertUnchanged(t, o)
+	})
+
+	t.Run("Error: declined", func(t *testing.T) {
+		o := newOptions()
+		o.yes = false
+		o.openTerminal = newTerminal("\n")
+
+		assert.EqualError(t, o.Run(), "aborted, no changes were made")
+		assertUnchanged(t, o)
+	})
+}
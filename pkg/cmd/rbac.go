@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// permission is an API request the command needs to be authorized for.
+type permission struct {
+	// verb is the API verb, e.g. get or patch.
+	verb string
+	// group is the API group of the resource, empty for the core group.
+	group string
+	// resource is the resource, e.g. nodes.
+	resource string
+	// subresource is the subresource, e.g. status.
+	subresource string
+	// namespace is the namespace of a namespaced resource, empty for cluster-scoped resources.
+	namespace string
+}
+
+// String formats the permission as it is written in RBAC rules, e.g. "patch nodes/status",
+// followed by the namespace of namespaced resources.
+func (p permission) String() string {
+	resource := p.resource
+	if p.group != "" {
+		resource += "." + p.group
+	}
+
+	if p.subresource != "" {
+		resource += "/" + p.subresource
+	}
+
+	if p.namespace != "" {
+		resource += " in namespace " + p.namespace
+	}
+
+	return p.verb + " " + resource
+}
+
+// Permissions needed to condition nodes.
+var (
+	getNodes        = permission{verb: "get", resource: "nodes"}
+	listNodesAccess = permission{verb: "list", resource: "nodes"}
+	patchNodeStatus = permission{verb: "patch", resource: "nodes", subresource: "status"}
+	createEvents    = permission{verb: "create", group: "events.k8s.io", resource: "events", namespace: metav1.NamespaceDefault}
+)
+
+// readNodesPermission returns the permission listNodes needs to read the named nodes, every node is listed when
+// no names are given.
+func readNodesPermission(nodeNames []string) permission {
+	if len(nodeNames) == 0 {
+		return listNodesAccess
+	}
+
+	return getNodes
+}
+
+// preflight checks with SelfSubjectAccessReviews that the user is authorized for every permission, so missing
+// permissions are reported up front instead of as Forbidden errors one node at a time. It returns an error listing
+// every missing permission, or the error of an access review that could not be performed.
+func preflight(client kubernetes.Interface, permissions ...permission) error {
+	missing, err := missingPermissions(client, permissions...)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("you are missing the following permissions, ask a cluster administrator to grant them:\n  - %s", strings.Join(missing, "\n  - "))
+}
+
+// missingPermissions returns the permissions the user is not authorized for, formatted as RBAC rules.
+func missingPermissions(client kubernetes.Interface, permissions ...permission) ([]string, error) {
+	var missing []string
+	for _, p := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   p.namespace,
+					Verb:        p.verb,
+					Group:       p.group,
+					Resource:    p.resource,
+					Subresource: p.subresource,
+				},
+			},
+		}
+
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(context.Background(), review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("checking permission to %s: %w", p, err)
+		}
+
+		if !review.Status.Allowed {
+			missing = append(missing, p.String())
+		}
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newAccessReviewClient returns a fake client allowing the permissions for which allowed returns true.
+func newAccessReviewClient(allowed func(*authorizationv1.ResourceAttributes) bool, err error) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed(review.Spec.ResourceAttributes)
+		return true, review, err
+	})
+
+	return client
+}
+
+func TestPreflight(t *testing.T) {
+	t.Run("Success: every permission is granted", func(t *testing.T) {
+		client := newAccessReviewClient(func(*authorizationv1.ResourceAttributes) bool { return true }, nil)
+		assert.NoError(t, preflight(client, getNodes, patchNodeStatus, createEvents))
+	})
+
+	t.Run("Error: missing permissions are listed", func(t *testing.T) {
+		client := newAccessReviewClient(func(attributes *authorizationv1.ResourceAttributes) bool {
+			return attributes.Verb == "get"
+		}, nil)
+
+		err := preflight(client, getNodes, patchNodeStatus, createEvents)
+		assert.EqualError(t, err, "you are missing the following permissions, ask a cluster administrator to grant them:\n"+
+			"  - patch nodes/status\n"+
+			"  - create events.events.k8s.io in namespace default")
+	})
+
+	t.Run("Success: namespaced permissions are reviewed in their namespace", func(t *testing.T) {
+		client := newAccessReviewClient(func(attributes *authorizationv1.ResourceAttributes) bool {
+			return attributes.Resource != "events" || attributes.Namespace == "default"
+		}, nil)
+		assert.NoError(t, preflight(client, createEvents))
+	})
+
+	t.Run("Error: the review fails", func(t *testing.T) {
+		client := newAccessReviewClient(func(*authorizationv1.ResourceAttributes) bool { return false }, errors.New("connection refused"))
+		assert.EqualError(t, preflight(client, getNodes), "checking permission to get nodes: connection refused")
+	})
+}
+
+func TestChangePreflight(t *testing.T) {
+	denied := func(attributes *authorizationv1.ResourceAttributes) bool {
+		return attributes.Verb != "list"
+	}
+
+	t.Run("Success: listing is not checked without a percentage limit", func(t *testing.T) {
+		o := &changeOptions{client: newAccessReviewClient(denied, nil)}
+		assert.NoError(t, o.preflight(getNodes))
+	})
+
+	t.Run("Error: listing is checked with a percentage limit", func(t *testing.T) {
+		o := &changeOptions{client: newAccessReviewClient(denied, nil), maxNodesPercent: 10}
+		assert.EqualError(t, o.preflight(getNodes), "you are missing the following permissions, ask a cluster administrator to grant them:\n"+
+			"  - list nodes")
+	})
+}
+
+func TestReadNodesPermission(t *testing.T) {
+	assert.Equal(t, listNodesAccess, readNodesPermission(nil))
+	assert.Equal(t, getNodes, readNodesPermission([]string{"worker-01"}))
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/devbytes-cloud/conditioner/pkg/jsonpatch"
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+var (
+	reapExample = `
+# Mark probe conditions that have not been heartbeated for 10 minutes as Unknown on every node
+kubectl conditioner reap --type example.com/ProbeHealthy --max-heartbeat-age 10m --set-status Unknown
+
+# Remove stale conditions matching a glob from specific nodes
+kubectl conditioner reap my-node --type 'example.com/*' --max-heartbeat-age 1h --remove
+`
+
+	reapLong = `The 'reap' command finds conditions whose 'lastHeartbeatTime' is older than '--max-heartbeat-age'.
+Conditions written by external probes go stale when the probe stops running, 'reap' marks them with the status
+given by '--set-status' (Unknown by default) and the reason 'StaleHeartbeat', or removes them with '--remove'.
+Without node names every node in the cluster is scanned, node names can also be piped in on stdin.
+The '--type' flag may be a glob or a regular expression wrapped in slashes. Like setting a condition, only the
+condition types the '--team' may use are reaped.`
+)
+
+// staleHeartbeatReason is the reason set on conditions marked by reap.
+const staleHeartbeatReason string = "StaleHeartbeat"
+
+// ReapOptions is a struct that holds the configuration for the reap command.
+type ReapOptions struct {
+	// changeOptions holds the client, the configuration and the recording of the changes shared with the
+	// conditioner command and prune.
+	changeOptions
+
+	// nodeNames are the names of the nodes to reap. When empty every node is reaped.
+	nodeNames []string
+
+	// conditionType is the condition type, glob or regular expression selecting the conditions to reap.
+	conditionType string
+
+	// maxHeartbeatAge is the age after which a condition's heartbeat is considered stale.
+	maxHeartbeatAge time.Duration
+
+	// status is the status stale conditions are set to.
+	status corev1.ConditionStatus
+
+	// remove indicates whether stale conditions are removed instead of having their status set.
+	remove bool
+
+	// forceProtected allows protected condition types to be reaped.
+	forceProtected bool
+
+	// team is the team reaping the conditions, only condition types the team may use are reaped.
+	team string
+}
+
+// NewReapOptions is a function that creates a new ReapOptions.
+func NewReapOptions(streams genericiooptions.IOStreams) *ReapOptions {
+	return &ReapOptions{
+		changeOptions: newChangeOptions(streams),
+	}
+}
+
+// NewCmdReap returns a cobra.Command that implements the reap subcommand.
+func NewCmdReap(streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewReapOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "reap [node name ...] [flags]",
+		Short:        "Mark or remove conditions with a stale heartbeat.",
+		Long:         reapLong,
+		Example:      reapExample,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			conf, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+
+			if err := o.Complete(c, args, conf); err != nil {
+				return err
+			}
+
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringP("type", "", "", "(required): type of condition to reap, may be a glob or /regex/")
+	cmd.Flags().DurationP("max-heartbeat-age", "", 0, "(required): age after which a condition's heartbeat is stale (e.g. 10m)")
+	cmd.Flags().StringP("set-status", "", string(corev1.ConditionUnknown), "Status stale conditions are set to [true, false, unknown]")
+	cmd.Flags().BoolP("remove", "x", false, "Remove stale conditions instead of setting their status")
+	cmd.Flags().BoolP("force-protected", "", false, "Allow protected condition types, such as the kubelet's built-in conditions, to be reaped")
+	cmd.Flags().StringP("team", "", "", "Team reaping the conditions, unprefixed condition types are prefixed with the team's prefix")
+
+	if err := cmd.MarkFlagRequired("type"); err != nil {
+		panic(fmt.Sprintf("failed to mark %s flag required: %s", "type", err.Error()))
+	}
+
+	if err := cmd.MarkFlagRequired("max-heartbeat-age"); err != nil {
+		panic(fmt.Sprintf("failed to mark %s flag required: %s", "max-heartbeat-age", err.Error()))
+	}
+
+	cmd.MarkFlagsMutuallyExclusive("set-status", "remove")
+
+	addChangeFlags(cmd)
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Complete creates the Kubernetes client and sets up the recording of the changes with completeChange, validates
+// the flags, collects the node names from args and stdin and checks the user is authorized to reap them.
+func (o *ReapOptions) Complete(cmd *cobra.Command, args []string, config *config.Config) error {
+	if err := o.completeChange(cmd, config); err != nil {
+		return err
+	}
+
+	var err error
+	o.team, err = cmd.Flags().GetString("team")
+	if err != nil {
+		return err
+	}
+
+	if o.team == "" {
+		o.team = o.config.DefaultTeam
+	}
+
+	if _, ok := o.config.Teams[o.team]; o.team != "" && !ok {
+		return fmt.Errorf("unknown team %s", o.team)
+	}
+
+	conditionType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return err
+	}
+
+	o.conditionType, err = o.config.QualifyType(o.team, conditionType)
+	if err != nil {
+		return err
+	}
+
+	if err := pattern.Validate(o.conditionType); err != nil {
+		return err
+	}
+
+	o.maxHeartbeatAge, err = cmd.Flags().GetDuration("max-heartbeat-age")
+	if err != nil {
+		return err
+	}
+
+	if o.maxHeartbeatAge <= 0 {
+		return fmt.Errorf("--max-heartbeat-age must be greater than zero")
+	}
+
+	status, err := cmd.Flags().GetString("set-status")
+	if err != nil {
+		return err
+	}
+
+	o.status, err = parseConditionStatus(status)
+	if err != nil {
+		return err
+	}
+
+	if o.status == "" {
+		return fmt.Errorf("--set-status %s is not supported by reap", keepStatus)
+	}
+
+	o.remove, err = cmd.Flags().GetBool("remove")
+	if err != nil {
+		return err
+	}
+
+	o.forceProtected, err = cmd.Flags().GetBool("force-protected")
+	if err != nil {
+		return err
+	}
+
+	o.nodeNames, err = collectNodeNames(o.In, args)
+	if err != nil {
+		return err
+	}
+
+	return o.preflight(readNodesPermission(o.nodeNames))
+}
+
+// Run reaps the stale conditions of the selected nodes.
+func (o *ReapOptions) Run() error {
+	return o.changeNodes(o.nodeNames, o.describe(), o.remove, o.conditionType, o.staleTypes, o.reapNode)
+}
+
+// describe describes the change reap makes for the confirmation prompt.
+func (o *ReapOptions) describe() string {
+	if o.remove {
+		return fmt.Sprintf("remove the stale conditions matching %s", o.conditionType)
+	}
+
+	return fmt.Sprintf("set the stale conditions matching %s to %s", o.conditionType, o.status)
+}
+
+// reapNode marks or removes every stale condition of the node in a single JSON Patch request, and records the changes.
+func (o *ReapOptions) reapNode(nodeName string) error {
+	var indices []int
+	var updates map[int]*corev1.NodeCondition
+	node, err := patchStatus(o.client, nodeName, func(node *corev1.Node) ([]interface{}, error) {
+		updates = map[int]*corev1.NodeCondition{}
+
+		var err error
+		indices, err = o.staleConditions(node.Status.Conditions)
+		if err != nil || len(indices) == 0 {
+			return nil, err
+		}
+
+		expiries := make(map[corev1.NodeConditionType]*time.Time, len(indices))
+		operations := make(map[corev1.NodeConditionType]string, len(indices))
+
+		var jsonPath []interface{}
+		if o.remove {
+			for _, index := range indices {
+				expiries[node.Status.Conditions[index].Type] = nil
+				operations[node.Status.Conditions[index].Type] = "remove"
+			}
+
+			jsonPath = jsonpatch.GenerateRemovePatches(node.Status.Conditions, indices)
+		} else {
+			for _, index := range indices {
+				old := node.Status.Conditions[index]
+				patch := jsonpatch.GenerateJsonPath(index, false, &old, &corev1.NodeCondition{
+					Type:    old.Type,
+					Status:  o.status,
+					Reason:  staleHeartbeatReason,
+					Message: fmt.Sprintf("no heartbeat received for more than %s, last heartbeat at %s", o.maxHeartbeatAge, old.LastHeartbeatTime.Format(time.RFC3339)),
+				})
+
+				// The condition has not actually been heartbeated, so the heartbeat is kept.
+				patch.Value.LastHeartbeatTime = old.LastHeartbeatTime
+
+				updates[index] = patch.Value
+				operations[old.Type] = patch.OP
+				jsonPath = append(jsonPath, jsonpatch.GenerateTestPatch(index, old.Type), patch)
+			}
+		}
+
+		annotations := map[string]*string{}
+		if err := updateExpiries(node, expiries, annotations); err != nil {
+			return nil, err
+		}
+
+		if err := o.recordAuthor(node, operations, annotations); err != nil {
+			return nil, err
+		}
+
+		return append(jsonPath, annotationPatches(node, annotations)...), nil
+	})
+	if node == nil {
+		return err
+	}
+
+	operation := "replace"
+	if o.remove {
+		operation = "remove"
+	}
+
+	for _, index := range indices {
+		o.recordAudit(node.Name, string(node.Status.Conditions[index].Type), operation, &node.Status.Conditions[index], updates[index], err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		action := "removed"
+		if !o.remove {
+			action = fmt.Sprintf("set to %s", o.status)
+		}
+
+		fmt.Fprintf(o.Out, "stale condition status %s has been %s on node %s\n", node.Status.Conditions[index].Type, action, node.Name)
+		o.emitEvent(node, &node.Status.Conditions[index], updates[index])
+	}
+
+	return nil
+}
+
+// staleTypes returns the condition types of the stale conditions of the node.
+func (o *ReapOptions) staleTypes(node *corev1.Node) ([]string, error) {
+	indices, err := o.staleConditions(node.Status.Conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	conditionTypes := make([]string, 0, len(indices))
+	for _, index := range indices {
+		conditionTypes = append(conditionTypes, string(node.Status.Conditions[index].Type))
+	}
+
+	return conditionTypes, nil
+}
+
+// staleConditions returns the indices of the conditions matching the condition type whose heartbeat is
+// older than the maximum heartbeat age. Conditions that were already reaped, conditions rejected by
+// the allow-list, deny-list or team prefixes, conditions whose policy disallows removal when removing, and protected
+// conditions without --force-protected, or protected by the cluster policy, are never selected. An invalid allow-list
+// or deny-list is returned as an error.
+func (o *ReapOptions) staleConditions(conditions []corev1.NodeCondition) ([]int, error) {
+	cutoff := o.now().Add(-o.maxHeartbeatAge)
+
+	var indices []int
+	for k, v := range conditions {
+		conditionType := string(v.Type)
+
+		ok, err := pattern.Match(o.conditionType, conditionType)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok || !v.LastHeartbeatTime.Time.Before(cutoff) {
+			continue
+		}
+
+		if !o.remove && v.Status == o.status && v.Reason == staleHeartbeatReason {
+			continue
+		}
+
+		if err := o.config.CheckType(conditionType); err != nil {
+			if config.IsRejected(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if err := o.config.CheckTeam(o.team, conditionType); err != nil {
+			if config.IsRejected(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if o.config.Protected(conditionType) && (!o.forceProtected || o.config.ClusterProtected(conditionType)) {
+			continue
+		}
+
+		if o.remove && validateRemove(conditionType, o.config) != nil {
+			continue
+		}
+
+		indices = append(indices, k)
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/audit"
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newProbedNode returns a node with a stale and a fresh probe condition at now.
+func newProbedNode(name string, now time.Time) *corev1.Node {
+	stale := metav1.NewTime(now.Add(-time.Hour))
+	fresh := metav1.NewTime(now.Add(-time.Minute))
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: stale},
+				{Type: "example.com/ProbeHealthy", Status: corev1.ConditionTrue, LastHeartbeatTime: stale, LastTransitionTime: stale},
+				{Type: "example.com/DiskProbe", Status: corev1.ConditionTrue, LastHeartbeatTime: fresh, LastTransitionTime: fresh},
+			},
+		},
+	}
+}
+
+func TestReapSetsStatus(t *testing.T) {
+	now := time.Now()
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewReapOptions(streams)
+	o.client = fake.NewClientset(newProbedNode("worker-01", now))
+	o.config = &config.Config{}
+	o.now = func() time.Time { return now }
+	o.conditionType = "example.com/*"
+	o.maxHeartbeatAge = 10 * time.Minute
+	o.status = corev1.ConditionUnknown
+
+	require.NoError(t, o.Run())
+
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	probe, _ := findConditionType(node.Status.Conditions, "example.com/ProbeHealthy")
+	assert.Equal(t, corev1.ConditionUnknown, probe.Status)
+	assert.Equal(t, staleHeartbeatReason, probe.Reason)
+	assert.WithinDuration(t, now.Add(-time.Hour), probe.LastHeartbeatTime.Time, time.Second)
+
+	disk, _ := findConditionType(node.Status.Conditions, "example.com/DiskProbe")
+	assert.Equal(t, corev1.ConditionTrue, disk.Status)
+
+	assert.Equal(t, "stale condition status example.com/ProbeHealthy has been set to Unknown on node worker-01\n", out.String())
+
+	// Reaping again does not touch the condition that was already reaped.
+	out.Reset()
+	require.NoError(t, o.Run())
+	assert.Empty(t, out.String())
+}
+
+func TestReapRemove(t *testing.T) {
+	now := time.Now()
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewReapOptions(streams)
+	o.client = fake.NewClientset(newProbedNode("worker-01", now))
+	o.config = &config.Config{}
+	o.now = func() time.Time { return now }
+	o.conditionType = "/.*/"
+	o.maxHeartbeatAge = 10 * time.Minute
+	o.remove = true
+	o.yes = true
+
+	require.NoError(t, o.Run())
+
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// Ready is stale too, but it is protected.
+	types := make([]corev1.NodeConditionType, 0, len(node.Status.Conditions))
+	for _, condition := range node.Status.Conditions {
+		types = append(types, condition.Type)
+	}
+	assert.Equal(t, []corev1.NodeConditionType{corev1.NodeReady, "example.com/DiskProbe"}, types)
+}
+
+func TestReapInvalidDenyList(t *testing.T) {
+	now := time.Now()
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewReapOptions(streams)
+	o.client = fake.NewClientset(newProbedNode("worker-01", now))
+	o.config = &config.Config{DenyList: []string{"/(broken/"}}
+	o.now = func() time.Time { return now }
+	o.conditionType = "example.com/*"
+	o.maxHeartbeatAge = 10 * time.Minute
+	o.remove = true
+	o.yes = true
+
+	err := o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deny-list: invalid regular expression /(broken/")
+
+	node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, node.Status.Conditions, 3)
+}
+
+func TestReapTeams(t *testing.T) {
+	now := time.Now()
+	conf := &config.Config{Teams: map[string]config.Team{"probes": {Prefix: "example.com"}}}
+
+	t.Run("Success: conditions of a team are skipped without the team", func(t *testing.T) {
+		o := NewReapOptions(genericiooptions.IOStreams{})
+		o.client = fake.NewClientset(newProbedNode("worker-01", now))
+		o.config = conf
+		o.now = func() time.Time { return now }
+		o.conditionType = "example.com/*"
+		o.maxHeartbeatAge = 10 * time.Minute
+		o.remove = true
+		o.yes = true
+
+		require.NoError(t, o.Run())
+
+		node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Len(t, node.Status.Conditions, 3)
+	})
+
+	t.Run("Success: conditions of the team are reaped", func(t *testing.T) {
+		streams, _, _, _ := genericiooptions.NewTestIOStreams()
+		o := NewReapOptions(streams)
+		o.client = fake.NewClientset(newProbedNode("worker-01", now))
+		o.config = conf
+		o.now = func() time.Time { return now }
+		o.conditionType = "example.com/*"
+		o.maxHeartbeatAge = 10 * time.Minute
+		o.remove = true
+		o.yes = true
+		o.team = "probes"
+
+		require.NoError(t, o.Run())
+
+		node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Len(t, node.Status.Conditions, 2)
+		_, index := findConditionType(node.Status.Conditions, "example.com/ProbeHealthy")
+		assert.Equal(t, -1, index)
+	})
+}
+
+func TestReapRecordsChanges(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+
+	o := NewReapOptions(streams)
+	o.client = fake.NewClientset(newProbedNode("worker-01", now))
+	o.config = &config.Config{}
+	o.now = func() time.Time { return now }
+	o.conditionType = "example.com/*"
+	o.maxHeartbeatAge = 10 * time.Minute
+	o.status = corev1.ConditionUnknown
+	o.auditLog = audit.New(path, 1024*1024, 1)
+	o.events = true
+	o.actor = "jane@example.com"
+
+	require.NoError(t, o.Run())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "worker-01", entry.Node)
+	assert.Equal(t, "example.com/ProbeHealthy", entry.ConditionType)
+	assert.Equal(t, "replace", entry.Operation)
+	assert.Equal(t, corev1.ConditionTrue, entry.Before.Status)
+	assert.Equal(t, corev1.ConditionUnknown, entry.After.Status)
+	assert.Equal(t, audit.ResultSuccess, entry.Result)
+
+	events, err := o.client.EventsV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, staleHeartbeatReason, events.Items[0].Reason)
+}
+
+func TestReapLimitsAndConfirmation(t *testing.T) {
+	now := time.Now()
+	newOptions := func() *ReapOptions {
+		o := NewReapOptions(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+		o.client = fake.NewClientset(newProbedNode("worker-01", now), newProbedNode("worker-02", now), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-03"}})
+		o.config = &config.Config{}
+		o.now = func() time.Time { return now }
+		o.conditionType = "example.com/*"
+		o.maxHeartbeatAge = 10 * time.Minute
+		o.status = corev1.ConditionUnknown
+
+		return o
+	}
+
+	assertUnchanged := func(t *testing.T, o *ReapOptions) {
+		node, err := o.client.CoreV1().Nodes().Get(context.Background(), "worker-01", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		probe, _ := findConditionType(node.Status.Conditions, "example.com/ProbeHealthy")
+		assert.Equal(t, corev1.ConditionTrue, probe.Status)
+	}
+
+	t.Run("Success: only nodes with stale conditions count against the limit", func(t *testing.T) {
+		o := newOptions()
+		o.maxNodes = 2
+
+		require.NoError(t, o.Run())
+	})
+
+	t.Run("Success: confirmed removal", func(t *testing.T) {
+		o := newOptions()
+		o.remove = true
+		o.openTerminal = newTerminal("y\n")
+
+		require.NoError(t, o.Run())
+		assert.Equal(t, "About to remove the stale conditions matching example.com/* on 2 node(s): worker-01, worker-02\nContinue? [y/N]: ", o.ErrOut.(*bytes.Buffer).String())
+	})
+
+	t.Run("Error: more than max-nodes", func(t *testing.T) {
+		o := newOptions()
+		o.maxNodes = 1
+
+		assert.EqualError(t, o.Run(), "refusing to change 2 nodes, the limit is 1 nodes, use --max-nodes to raise it")
+		assertUnchanged(t, o)
+	})
+
+	t.Run("Error: declined removal", func(t *testing.T) {
+		o := newOptions()
+		o.remove = true
+		o.openTerminal = newTerminal("n\n")
+
+		assert.EqualError(t, o.Run(), "aborted, no changes were made")
+		assertUnchanged(t, o)
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"io"
+	"os"
+)
+
+// openTerminal opens the controlling terminal. The prompt is read from it rather than from stdin,
+// as stdin may be the node list piped in by 'kubectl get nodes -o name'.
+func openTerminal() (io.ReadCloser, error) {
+	return os.Open("/dev/tty")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxReasonLength is the maximum length of a condition reason, as enforced by Kubernetes on metav1.Condition.
+const maxReasonLength int = 1024
+
+// reasonRegexp matches a CamelCase reason, following the validation of metav1.Condition.
+var reasonRegexp = regexp.MustCompile(`^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$`)
+
+// validateCondition checks the reason and message of the condition against the Kubernetes
+// conventions and the policy of its type. An empty status, reason or message is left alone,
+// as it keeps the value of an existing condition.
+func validateCondition(condition *corev1.NodeCondition, conf *config.Config) error {
+	if condition.Reason != "" {
+		if len(condition.Reason) > maxReasonLength {
+			return fmt.Errorf("reason must be no more than %d characters", maxReasonLength)
+		}
+
+		if !reasonRegexp.MatchString(condition.Reason) {
+			return fmt.Errorf("reason %q must be a CamelCase identifier: it must start with a letter and may only contain letters, digits, '_', ',' and ':'", condition.Reason)
+		}
+	}
+
+	if limit := conf.MessageLimit(); len(condition.Message) > limit {
+		return fmt.Errorf("message is %d characters long, it must be no more than %d characters", len(condition.Message), limit)
+	}
+
+	return validatePolicy(condition, conf.Policy(string(condition.Type)))
+}
+
+// validatePolicy checks the condition against the policy of its type.
+func validatePolicy(condition *corev1.NodeCondition, policy config.TypePolicy) error {
+	if len(policy.Statuses) != 0 && condition.Status != "" && !slices.ContainsFunc(policy.Statuses, func(status string) bool {
+		return strings.EqualFold(status, string(condition.Status))
+	}) {
+		return fmt.Errorf("status %s is not allowed for condition %s, must be one of %v", condition.Status, condition.Type, policy.Statuses)
+	}
+
+	if len(policy.Reasons) != 0 && condition.Reason != "" && !slices.Contains(policy.Reasons, condition.Reason) {
+		return fmt.Errorf("reason %s is not allowed for condition %s, must be one of %v", condition.Reason, condition.Type, policy.Reasons)
+	}
+
+	if policy.ReasonPattern != "" && condition.Reason != "" {
+		ok, err := regexp.MatchString(policy.ReasonPattern, condition.Reason)
+		if err != nil {
+			return fmt.Errorf("invalid reason-pattern %q for condition %s: %w", policy.ReasonPattern, condition.Type, err)
+		}
+
+		if !ok {
+			return fmt.Errorf("reason %s is not allowed for condition %s, must match %q", condition.Reason, condition.Type, policy.ReasonPattern)
+		}
+	}
+
+	if policy.RequireMessage && condition.Message == "" {
+		return fmt.Errorf("condition %s requires a --message", condition.Type)
+	}
+
+	return nil
+}
+
+// validateRemove checks that the policy of the condition type permits removing it.
+func validateRemove(conditionType string, conf *config.Config) error {
+	if conf.Policy(conditionType).DisallowRemove {
+		return fmt.Errorf("condition %s may not be removed, its policy disallows removal", conditionType)
+	}
+
+	return nil
+}
+
+// nodeSelector parses the node selector of the condition type's policy.
+// It returns nil if the policy does not restrict the nodes the condition type may be set on.
+func nodeSelector(conditionType string, conf *config.Config) (labels.Selector, error) {
+	raw := conf.Policy(conditionType).NodeSelector
+	if raw == "" {
+		return nil, nil
+	}
+
+	selector, err := labels.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node-selector %q for condition %s: %w", raw, conditionType, err)
+	}
+
+	return selector, nil
+}
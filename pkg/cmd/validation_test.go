@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestValidateCondition(t *testing.T) {
+	conf := &config.Config{
+		MaxMessageLength: 16,
+		Policies: map[string]config.TypePolicy{
+			"NodeMaintenance": {Reasons: []string{"MaintenanceScheduled", "MaintenanceComplete"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		condition corev1.NodeCondition
+		wantErr   string
+	}{
+		{"Valid", corev1.NodeCondition{Type: "Other", Reason: "Probe_Failed:Disk", Message: "short"}, ""},
+		{"Empty reason and message", corev1.NodeCondition{Type: "Other"}, ""},
+		{"Reason with spaces", corev1.NodeCondition{Type: "Other", Reason: "probe failed"}, "must be a CamelCase identifier"},
+		{"Reason starting with a digit", corev1.NodeCondition{Type: "Other", Reason: "1Failed"}, "must be a CamelCase identifier"},
+		{"Reason ending with a colon", corev1.NodeCondition{Type: "Other", Reason: "Failed:"}, "must be a CamelCase identifier"},
+		{"Reason too long", corev1.NodeCondition{Type: "Other", Reason: "A" + strings.Repeat("a", maxReasonLength)}, "no more than 1024 characters"},
+		{"Message too long", corev1.NodeCondition{Type: "Other", Message: strings.Repeat("m", 17)}, "it must be no more than 16 characters"},
+		{"Reason in enum", corev1.NodeCondition{Type: "NodeMaintenance", Reason: "MaintenanceComplete"}, ""},
+		{"Reason not in enum", corev1.NodeCondition{Type: "NodeMaintenance", Reason: "Whatever"}, "reason Whatever is not allowed for condition NodeMaintenance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCondition(&tt.condition, conf)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	policy := config.TypePolicy{
+		Statuses:       []string{"true", "false"},
+		ReasonPattern:  "^Maintenance",
+		RequireMessage: true,
+	}
+
+	tests := []struct {
+		name      string
+		condition corev1.NodeCondition
+		wantErr   string
+	}{
+		{"Valid", corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled", Message: "soon"}, ""},
+		{"Kept status", corev1.NodeCondition{Type: "NodeMaintenance", Message: "soon"}, ""},
+		{"Status not allowed", corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionUnknown, Message: "soon"}, "status Unknown is not allowed for condition NodeMaintenance, must be one of [true false]"},
+		{"Reason does not match", corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "Scheduled", Message: "soon"}, `reason Scheduled is not allowed for condition NodeMaintenance, must match "^Maintenance"`},
+		{"Missing message", corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue}, "condition NodeMaintenance requires a --message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePolicy(&tt.condition, policy)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateRemove(t *testing.T) {
+	conf := &config.Config{Policies: map[string]config.TypePolicy{"NodeMaintenance": {DisallowRemove: true}}}
+
+	assert.NoError(t, validateRemove("Other", conf))
+	assert.EqualError(t, validateRemove("NodeMaintenance", conf), "condition NodeMaintenance may not be removed, its policy disallows removal")
+}
+
+func TestNodeSelector(t *testing.T) {
+	conf := &config.Config{Policies: map[string]config.TypePolicy{
+		"GPUHealthy": {NodeSelector: "accelerator=nvidia"},
+		"Broken":     {NodeSelector: "a in (b"},
+	}}
+
+	selector, err := nodeSelector("Other", conf)
+	assert.NoError(t, err)
+	assert.Nil(t, selector)
+
+	selector, err = nodeSelector("GPUHealthy", conf)
+	assert.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set{"accelerator": "nvidia"}))
+	assert.False(t, selector.Matches(labels.Set{}))
+
+	_, err = nodeSelector("Broken", conf)
+	assert.ErrorContains(t, err, `invalid node-selector "a in (b" for condition Broken`)
+}
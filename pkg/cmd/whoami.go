@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// identity is the identity of the user running the plugin, along with the message it is embedded in.
+// It is the data the whoami-format template is executed with.
+type identity struct {
+	// Username is the name of the user.
+	Username string
+	// UID is the unique ID of the user, it is empty for operating system users.
+	UID string
+	// Groups are the groups the user belongs to, they are empty for operating system users.
+	Groups []string
+	// Message is the condition message the identity is embedded in.
+	Message string
+}
+
+// whoAmI returns the identity of the user running the plugin, from the source selected by the configuration.
+// The Kubernetes identity is the one the API server authenticates the client as, so it honours --as and
+// --as-group impersonation, which the client created from the configFlags carries.
+func whoAmI(client kubernetes.Interface, conf *config.Config) (identity, error) {
+	switch source := conf.IdentitySource(); source {
+	case config.WhoAmISourceOS:
+		u, err := user.Current()
+		if err != nil {
+			return identity{}, err
+		}
+
+		return identity{Username: u.Username}, nil
+	case config.WhoAmISourceKubernetes:
+		review, err := client.AuthenticationV1().SelfSubjectReviews().Create(context.Background(), &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+		if err != nil {
+			return identity{}, fmt.Errorf("determining kubernetes identity: %w", err)
+		}
+
+		userInfo := review.Status.UserInfo
+		if userInfo.Username == "" {
+			return identity{}, fmt.Errorf("determining kubernetes identity: the API server did not report a username")
+		}
+
+		return identity{Username: userInfo.Username, UID: userInfo.UID, Groups: userInfo.Groups}, nil
+	default:
+		return identity{}, fmt.Errorf("unknown whoami-source %q, must be one of [%s %s]", source, config.WhoAmISourceOS, config.WhoAmISourceKubernetes)
+	}
+}
+
+// whoAmIMessage embeds the identity of the user running the plugin in the message using the whoami-format template.
+func whoAmIMessage(client kubernetes.Interface, conf *config.Config, message string) (string, error) {
+	tmpl, err := template.New("whoami-format").Parse(conf.IdentityFormat())
+	if err != nil {
+		return "", fmt.Errorf("whoami-format: %w", err)
+	}
+
+	id, err := whoAmI(client, conf)
+	if err != nil {
+		return "", err
+	}
+
+	id.Message = message
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, id); err != nil {
+		return "", fmt.Errorf("whoami-format: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// sensitiveFlags are the flags whose values are redacted from recorded command lines.
+var sensitiveFlags = []string{"--token", "--password"}
+
+// newAuthorRecord returns the record of a change made by the user running the plugin.
+// The time and operation are filled in when the change is made.
+func newAuthorRecord(client kubernetes.Interface, conf *config.Config) (*authorRecord, error) {
+	id, err := whoAmI(client, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorRecord{Who: id.Username, Host: host, Command: commandLine(os.Args)}, nil
+}
+
+// commandLine joins the arguments into a command line, redacting the values of sensitiveFlags.
+func commandLine(args []string) string {
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		for _, flag := range sensitiveFlags {
+			switch {
+			case arg == flag && i+1 < len(args):
+				redacted = append(redacted, arg)
+				arg = "REDACTED"
+				i++
+			case strings.HasPrefix(arg, flag+"="):
+				arg = flag + "=REDACTED"
+			}
+		}
+
+		redacted = append(redacted, arg)
+	}
+
+	return strings.Join(redacted, " ")
+}
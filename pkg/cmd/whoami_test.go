@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"os/user"
+	"testing"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newSelfSubjectReviewClient returns a fake client answering SelfSubjectReviews with the user info.
+func newSelfSubjectReviewClient(userInfo authenticationv1.UserInfo, err error) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authenticationv1.SelfSubjectReview{Status: authenticationv1.SelfSubjectReviewStatus{UserInfo: userInfo}}, err
+	})
+
+	return client
+}
+
+func TestWhoAmIMessage(t *testing.T) {
+	userInfo := authenticationv1.UserInfo{Username: "jane@example.com", UID: "1234", Groups: []string{"sre", "system:authenticated"}}
+
+	t.Run("Success: os user with default format", func(t *testing.T) {
+		u, err := user.Current()
+		require.NoError(t, err)
+
+		message, err := whoAmIMessage(fake.NewClientset(), &config.Config{}, "draining")
+		require.NoError(t, err)
+		assert.Equal(t, u.Username+": draining", message)
+	})
+
+	t.Run("Success: kubernetes identity", func(t *testing.T) {
+		conf := &config.Config{WhoAmISource: config.WhoAmISourceKubernetes}
+
+		message, err := whoAmIMessage(newSelfSubjectReviewClient(userInfo, nil), conf, "draining")
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com: draining", message)
+	})
+
+	t.Run("Success: custom format", func(t *testing.T) {
+		conf := &config.Config{
+			WhoAmISource: config.WhoAmISourceKubernetes,
+			WhoAmIFormat: `{{.Message}} (set by {{.Username}} in {{index .Groups 0}})`,
+		}
+
+		message, err := whoAmIMessage(newSelfSubjectReviewClient(userInfo, nil), conf, "draining")
+		require.NoError(t, err)
+		assert.Equal(t, "draining (set by jane@example.com in sre)", message)
+	})
+
+	t.Run("Error: review fails", func(t *testing.T) {
+		conf := &config.Config{WhoAmISource: config.WhoAmISourceKubernetes}
+
+		_, err := whoAmIMessage(newSelfSubjectReviewClient(userInfo, errors.New("forbidden")), conf, "draining")
+		assert.EqualError(t, err, "determining kubernetes identity: forbidden")
+	})
+
+	t.Run("Error: no username", func(t *testing.T) {
+		conf := &config.Config{WhoAmISource: config.WhoAmISourceKubernetes}
+
+		_, err := whoAmIMessage(newSelfSubjectReviewClient(authenticationv1.UserInfo{}, nil), conf, "draining")
+		assert.EqualError(t, err, "determining kubernetes identity: the API server did not report a username")
+	})
+
+	t.Run("Error: unknown source", func(t *testing.T) {
+		_, err := whoAmIMessage(fake.NewClientset(), &config.Config{WhoAmISource: "ldap"}, "draining")
+		assert.EqualError(t, err, `unknown whoami-source "ldap", must be one of [os kubernetes]`)
+	})
+
+	t.Run("Error: invalid format", func(t *testing.T) {
+		_, err := whoAmIMessage(fake.NewClientset(), &config.Config{WhoAmIFormat: "{{.Username"}, "draining")
+		assert.ErrorContains(t, err, "whoami-format: template: whoami-format:1: unclosed action")
+	})
+}
+
+func TestCommandLine(t *testing.T) {
+	assert.Equal(t, "kubectl-conditioner worker-01 --type NodeMaintenance", commandLine([]string{"kubectl-conditioner", "worker-01", "--type", "NodeMaintenance"}))
+	assert.Equal(t, "kubectl-conditioner worker-01 --token REDACTED --password=REDACTED", commandLine([]string{"kubectl-conditioner", "worker-01", "--token", "secret", "--password=hunter2"}))
+	assert.Equal(t, "kubectl-conditioner --token", commandLine([]string{"kubectl-conditioner", "--token"}))
+}
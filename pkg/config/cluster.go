@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ClusterPolicyKey is the key of the ConfigMap data holding the cluster policy.
+const ClusterPolicyKey string = "policy.yaml"
+
+// DefaultClusterPolicy is the namespace/name of the ConfigMap holding the cluster policy. It is always looked up,
+// so a cluster administrator can enforce a policy without relying on the local configuration of every user.
+const DefaultClusterPolicy string = "kube-system/conditioner-policy"
+
+// ClusterPolicyRef splits the cluster-policy setting into the namespace and name of the ConfigMap.
+// It returns empty strings if no cluster policy is configured.
+func (c *Config) ClusterPolicyRef() (string, string, error) {
+	if c.ClusterPolicy == "" {
+		return "", "", nil
+	}
+
+	return SplitClusterPolicyRef(c.ClusterPolicy)
+}
+
+// ClusterPolicyRefs returns the namespace/name of every ConfigMap holding a cluster policy, in the order the policies
+// are applied. The ConfigMap named by the cluster-policy setting comes first, the DefaultClusterPolicy is always
+// looked up and applied last, so the local configuration can add a policy but never replace or skip the default one.
+func (c *Config) ClusterPolicyRefs() []string {
+	if c.ClusterPolicy == "" || c.ClusterPolicy == DefaultClusterPolicy {
+		return []string{DefaultClusterPolicy}
+	}
+
+	return []string{c.ClusterPolicy, DefaultClusterPolicy}
+}
+
+// SplitClusterPolicyRef splits a namespace/name reference to a cluster policy ConfigMap.
+func SplitClusterPolicyRef(ref string) (string, string, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("cluster-policy must be of the form namespace/name, not %q", ref)
+	}
+
+	return namespace, name, nil
+}
+
+// ParseClusterPolicy strictly decodes the cluster policy stored in a ConfigMap. The policy uses the same fields
+// as a Profile and may be written as YAML or JSON.
+func ParseClusterPolicy(data []byte) (Profile, error) {
+	var policy Profile
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return Profile{}, err
+	}
+
+	return policy, nil
+}
+
+// WithClusterPolicy returns the configuration with the cluster policy applied. The cluster policy takes
+// precedence over the local configuration: the fields it sets replace the local values, and its policies
+// and teams replace the local entries of the same name. Its deny-list and protected-types are added to the local
+// ones instead, so the cluster policy cannot allow what the local configuration restricts. Its max-nodes,
+// max-nodes-percent and protected-types are also recorded as restrictions flags cannot lift, see ClusterLimits
+// and ClusterProtected.
+func (c *Config) WithClusterPolicy(policy Profile) (*Config, error) {
+	if errs := c.validateProfile(policy); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid cluster policy:\n%w", errors.Join(errs...))
+	}
+
+	applied := c.apply(policy)
+
+	if policy.DenyList != nil {
+		applied.DenyList = union(c.DenyList, policy.DenyList)
+	}
+
+	if policy.ProtectedTypes != nil {
+		protected := c.ProtectedTypes
+		if protected == nil {
+			protected = DefaultProtectedTypes
+		}
+
+		applied.ProtectedTypes = union(protected, policy.ProtectedTypes)
+		applied.clusterProtectedTypes = union(c.clusterProtectedTypes, policy.ProtectedTypes)
+	}
+
+	applied.clusterMaxNodes = TighterLimit(c.clusterMaxNodes, policy.MaxNodes)
+	applied.clusterMaxNodesPercent = TighterLimit(c.clusterMaxNodesPercent, policy.MaxNodesPercent)
+
+	return applied, nil
+}
+
+// ClusterLimits returns the max-nodes and max-nodes-percent limits enforced by the cluster policies applied to the
+// configuration, the tightest one when several policies set a limit. Zero means no cluster policy sets the limit.
+func (c *Config) ClusterLimits() (int, int) {
+	return c.clusterMaxNodes, c.clusterMaxNodesPercent
+}
+
+// TighterLimit returns the tighter of two limits, where zero means no limit.
+func TighterLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
+
+// union returns the entries of a followed by the entries of b that are not in a.
+func union(a, b []string) []string {
+	merged := slices.Clone(a)
+	for _, entry := range b {
+		if !slices.Contains(merged, entry) {
+			merged = append(merged, entry)
+		}
+	}
+
+	return merged
+}
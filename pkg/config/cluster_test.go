@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterPolicyRef(t *testing.T) {
+	namespace, name, err := (&Config{ClusterPolicy: "kube-system/conditioner-policy"}).ClusterPolicyRef()
+	require.NoError(t, err)
+	assert.Equal(t, "kube-system", namespace)
+	assert.Equal(t, "conditioner-policy", name)
+
+	_, name, err = (&Config{}).ClusterPolicyRef()
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	for _, ref := range []string{"conditioner-policy", "/conditioner-policy", "kube-system/", "a/b/c"} {
+		_, _, err := (&Config{ClusterPolicy: ref}).ClusterPolicyRef()
+		assert.EqualError(t, err, `cluster-policy must be of the form namespace/name, not "`+ref+`"`)
+	}
+}
+
+func TestClusterPolicyRefs(t *testing.T) {
+	assert.Equal(t, []string{DefaultClusterPolicy}, (&Config{}).ClusterPolicyRefs())
+	assert.Equal(t, []string{DefaultClusterPolicy}, (&Config{ClusterPolicy: DefaultClusterPolicy}).ClusterPolicyRefs())
+	assert.Equal(t, []string{"platform/node-policy", DefaultClusterPolicy}, (&Config{ClusterPolicy: "platform/node-policy"}).ClusterPolicyRefs())
+}
+
+func TestParseClusterPolicy(t *testing.T) {
+	policy, err := ParseClusterPolicy([]byte("allow-list: [NodeMaintenance]\npolicies:\n  NodeMaintenance:\n    reasons: [MaintenanceScheduled]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NodeMaintenance"}, policy.AllowList)
+	assert.Equal(t, []string{"MaintenanceScheduled"}, policy.Policies["NodeMaintenance"].Reasons)
+
+	_, err = ParseClusterPolicy([]byte(`{"allowlist": ["NodeMaintenance"]}`))
+	assert.ErrorContains(t, err, `unknown field "allowlist"`)
+}
+
+func TestWithClusterPolicy(t *testing.T) {
+	local := &Config{
+		WhoAmI:    true,
+		AllowList: []string{},
+		Policies: map[string]TypePolicy{
+			"NodeMaintenance": {RequireMessage: true},
+			"Healthy":         {DisallowRemove: true},
+		},
+	}
+
+	t.Run("Cluster policy takes precedence", func(t *testing.T) {
+		got, err := local.WithClusterPolicy(Profile{
+			AllowList:      []string{"NodeMaintenance", "Healthy"},
+			ProtectedTypes: []string{"Ready"},
+			Policies:       map[string]TypePolicy{"NodeMaintenance": {Reasons: []string{"MaintenanceScheduled"}}},
+		})
+		require.NoError(t, err)
+
+		assert.True(t, got.WhoAmI)
+		assert.Equal(t, []string{"NodeMaintenance", "Healthy"}, got.AllowList)
+		assert.Equal(t, DefaultProtectedTypes, got.ProtectedTypes)
+		assert.Equal(t, TypePolicy{Reasons: []string{"MaintenanceScheduled"}}, got.Policies["NodeMaintenance"])
+		assert.Equal(t, TypePolicy{DisallowRemove: true}, got.Policies["Healthy"])
+	})
+
+	t.Run("Cluster policy adds to the deny-list and protected types", func(t *testing.T) {
+		restricted := &Config{DenyList: []string{"Legacy"}, ProtectedTypes: []string{"example.com/Owned"}}
+
+		got, err := restricted.WithClusterPolicy(Profile{DenyList: []string{"Deprecated"}, ProtectedTypes: []string{"Ready"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Legacy", "Deprecated"}, got.DenyList)
+		assert.Equal(t, []string{"example.com/Owned", "Ready"}, got.ProtectedTypes)
+		assert.True(t, got.ClusterProtected("Ready"))
+		assert.False(t, got.ClusterProtected("example.com/Owned"))
+		assert.False(t, restricted.ClusterProtected("Ready"))
+	})
+
+	t.Run("Cluster policies enforce their tightest limits", func(t *testing.T) {
+		got, err := local.WithClusterPolicy(Profile{MaxNodes: 10, MaxNodesPercent: 50})
+		require.NoError(t, err)
+
+		got, err = got.WithClusterPolicy(Profile{MaxNodes: 20, MaxNodesPercent: 25})
+		require.NoError(t, err)
+
+		maxNodes, maxNodesPercent := got.ClusterLimits()
+		assert.Equal(t, 10, maxNodes)
+		assert.Equal(t, 25, maxNodesPercent)
+
+		maxNodes, maxNodesPercent = local.ClusterLimits()
+		assert.Zero(t, maxNodes)
+		assert.Zero(t, maxNodesPercent)
+	})
+
+	t.Run("Invalid cluster policy", func(t *testing.T) {
+		_, err := local.WithClusterPolicy(Profile{AllowList: []string{"[broken"}})
+		assert.EqualError(t, err, "invalid cluster policy:\nallow-list: invalid glob [broken: syntax error in pattern")
+	})
+}
+
+func TestTighterLimit(t *testing.T) {
+	assert.Equal(t, 0, TighterLimit(0, 0))
+	assert.Equal(t, 5, TighterLimit(0, 5))
+	assert.Equal(t, 5, TighterLimit(5, 0))
+	assert.Equal(t, 3, TighterLimit(5, 3))
+	assert.Equal(t, 3, TighterLimit(3, 5))
+}
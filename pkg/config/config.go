@@ -3,31 +3,408 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
 	"github.com/mitchellh/go-homedir"
+	"sigs.k8s.io/yaml"
 )
 
-// ConfigName is the name of the configuration file.
+// ConfigName is the name of the legacy configuration file.
 const configName string = "~/.conditioner.json"
 
+// xdgConfigName is the path of the configuration file relative to the XDG config directory.
+const xdgConfigName string = "conditioner/config.yaml"
+
+// EnvConfig is the environment variable overriding the path of the configuration file.
+const EnvConfig string = "CONDITIONER_CONFIG"
+
+// DefaultProtectedTypes are the built-in condition types owned by the kubelet and the node controller.
+// They are protected from modification unless the configuration says otherwise, and are the conditions
+// --remove-all-custom keeps.
+var DefaultProtectedTypes = []string{"Ready", "MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable"}
+
+// DefaultMaxMessageLength is the maximum condition message length used when the configuration does not set one.
+// It matches the limit Kubernetes enforces on metav1.Condition messages.
+const DefaultMaxMessageLength int = 32768
+
+const (
+	// WhoAmISourceOS uses the name of the operating system user running the plugin as the identity.
+	WhoAmISourceOS string = "os"
+	// WhoAmISourceKubernetes uses the identity the Kubernetes API server authenticates the plugin as,
+	// as reported by a SelfSubjectReview.
+	WhoAmISourceKubernetes string = "kubernetes"
+)
+
+// DefaultWhoAmIFormat is the whoami format used when the configuration does not set one.
+const DefaultWhoAmIFormat string = "{{.Username}}: {{.Message}}"
+
+// DefaultConfirmThreshold is the number of nodes above which a change has to be confirmed when the configuration
+// does not set a threshold.
+const DefaultConfirmThreshold int = 10
+
+// DefaultAuditLogMaxSize is the size in megabytes after which the audit log is rotated when the configuration
+// does not set one.
+const DefaultAuditLogMaxSize int = 10
+
+// DefaultAuditLogMaxBackups is the number of rotated audit logs kept when the configuration does not set one.
+const DefaultAuditLogMaxBackups int = 5
+
 // Config represents the conditioners configuration.
 // It includes fields for user preferences and settings.
 type Config struct {
+	// Version is the version of the configuration schema, see SchemaVersion.
+	Version int `json:"version,omitempty"`
 	// WhoAmI indicates whether to prepend the user's identity to the output.
 	WhoAmI bool `json:"prepend-whoami"`
+	// WhoAmISource selects where the identity prepended by WhoAmI comes from, WhoAmISourceOS (the default)
+	// or WhoAmISourceKubernetes.
+	WhoAmISource string `json:"whoami-source,omitempty"`
+	// WhoAmIFormat is the text/template embedding the identity in the message. When unset it defaults to
+	// DefaultWhoAmIFormat. The template may use .Username, .UID, .Groups and .Message.
+	WhoAmIFormat string `json:"whoami-format,omitempty"`
+	// RecordAuthor indicates whether who changed each condition, when, from which host and with which
+	// command line is recorded in a node annotation.
+	RecordAuthor bool `json:"record-author,omitempty"`
+	// Events indicates whether an Event regarding the node is created for every condition change.
+	Events bool `json:"events,omitempty"`
+	// AuditLog is the path of the local JSON-lines audit log recording every change. No audit log is written when empty.
+	AuditLog string `json:"audit-log,omitempty"`
+	// AuditLogMaxSize is the size in megabytes after which the audit log is rotated.
+	// When unset it defaults to DefaultAuditLogMaxSize.
+	AuditLogMaxSize int `json:"audit-log-max-size,omitempty"`
+	// AuditLogMaxBackups is the number of rotated audit logs kept. When unset it defaults to DefaultAuditLogMaxBackups.
+	AuditLogMaxBackups int `json:"audit-log-max-backups,omitempty"`
+	// ConfirmThreshold is the number of nodes above which the user is asked to confirm a change.
+	// Removing conditions always asks for confirmation. When unset it defaults to DefaultConfirmThreshold.
+	ConfirmThreshold int `json:"confirm-threshold,omitempty"`
+	// MaxNodes is the maximum number of nodes a single invocation may change. No limit applies when unset.
+	MaxNodes int `json:"max-nodes,omitempty"`
+	// MaxNodesPercent is the maximum percentage of the cluster's nodes a single invocation may change.
+	// No limit applies when unset.
+	MaxNodesPercent int `json:"max-nodes-percent,omitempty"`
+	// clusterMaxNodes and clusterMaxNodesPercent are the tightest limits set by the cluster policies applied to
+	// the configuration. Unlike MaxNodes and MaxNodesPercent they cannot be raised or disabled by flags.
+	clusterMaxNodes        int
+	clusterMaxNodesPercent int
+	// clusterProtectedTypes are the condition types protected by the cluster policies applied to the configuration.
+	// Unlike ProtectedTypes they cannot be modified with --force-protected.
+	clusterProtectedTypes []string
+	// Webhooks are notified when conditions change.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
 	// AllowList is a list of allowed entities for the application.
+	// Entries may be condition types, globs (e.g. team-a.example.com/*) or regular expressions wrapped in slashes.
 	AllowList []string `json:"allow-list"`
+	// DenyList is a list of condition types, globs or regular expressions that may never be used.
+	// It takes precedence over AllowList.
+	DenyList []string `json:"deny-list,omitempty"`
+	// ProtectedTypes is a list of condition types that may only be modified or removed with --force-protected.
+	// When unset it defaults to DefaultProtectedTypes, an empty list disables the protection.
+	ProtectedTypes []string `json:"protected-types"`
+	// MaxMessageLength is the maximum length of a condition message. When unset it defaults to DefaultMaxMessageLength.
+	MaxMessageLength int `json:"max-message-length,omitempty"`
+	// Policies holds the policy of individual condition types, keyed by condition type.
+	Policies map[string]TypePolicy `json:"policies,omitempty"`
+	// Teams holds the teams setting conditions, keyed by team name.
+	Teams map[string]Team `json:"teams,omitempty"`
+	// DefaultTeam is the team used when --team is not provided.
+	DefaultTeam string `json:"default-team,omitempty"`
+	// Freezes are change freezes during which conditions may only be changed with --override-freeze.
+	Freezes []Freeze `json:"freezes,omitempty"`
+	// Profiles holds overrides for individual kubeconfig contexts or clusters, keyed by context or cluster name.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// ClusterPolicy is the namespace/name of an additional ConfigMap holding a policy that takes precedence over
+	// the local configuration. The DefaultClusterPolicy is always loaded and takes precedence over it.
+	ClusterPolicy string `json:"cluster-policy,omitempty"`
 }
 
-// Exists checks if the configuration file exists.
-// It returns a boolean indicating the existence of the file and any error encountered.
-func Exists(fs Filesystem) (bool, error) {
-	path, err := getPath()
+// Team is a team owning the condition types under its prefix.
+type Team struct {
+	// Prefix is the condition type prefix owned by the team, e.g. storage.example.com.
+	Prefix string `json:"prefix"`
+}
+
+// Effective returns a copy of the configuration with the built-in defaults filled in for unset fields.
+func (c *Config) Effective() *Config {
+	effective := *c
+
+	if effective.AllowList == nil {
+		effective.AllowList = []string{}
+	}
+
+	if effective.ProtectedTypes == nil {
+		effective.ProtectedTypes = slices.Clone(DefaultProtectedTypes)
+	}
+
+	effective.MaxMessageLength = c.MessageLimit()
+	effective.WhoAmISource = c.IdentitySource()
+	effective.WhoAmIFormat = c.IdentityFormat()
+	effective.ConfirmThreshold = c.ConfirmationThreshold()
+
+	maxSize, maxBackups := c.AuditLogRotation()
+	effective.AuditLogMaxSize = int(maxSize / (1024 * 1024))
+	effective.AuditLogMaxBackups = maxBackups
+
+	return &effective
+}
+
+// RejectedError is returned when the configuration does not allow a condition type to be used.
+// It tells a rejected condition type apart from an invalid configuration, such as a malformed pattern.
+type RejectedError struct {
+	msg string
+}
+
+// Error returns the reason the condition type was rejected.
+func (e *RejectedError) Error() string {
+	return e.msg
+}
+
+// IsRejected reports whether err is a RejectedError.
+func IsRejected(err error) bool {
+	var rejected *RejectedError
+	return errors.As(err, &rejected)
+}
+
+// rejectf returns a RejectedError with the formatted message.
+func rejectf(format string, args ...interface{}) error {
+	return &RejectedError{msg: fmt.Sprintf(format, args...)}
+}
+
+// CheckType checks whether the condition type may be used.
+// A condition type matching the deny-list is never allowed, otherwise it must match the allow-list
+// unless the allow-list is empty. The returned RejectedError explains which list rejected the condition type,
+// any other error means one of the lists is invalid.
+func (c *Config) CheckType(conditionType string) error {
+	denied, err := matchAny(c.DenyList, conditionType)
 	if err != nil {
-		return false, err
+		return fmt.Errorf("deny-list: %w", err)
+	}
+
+	if denied {
+		return rejectf("condition %s is in deny-list %v", conditionType, c.DenyList)
+	}
+
+	if len(c.AllowList) == 0 {
+		return nil
+	}
+
+	allowed, err := matchAny(c.AllowList, conditionType)
+	if err != nil {
+		return fmt.Errorf("allow-list: %w", err)
+	}
+
+	if !allowed {
+		return rejectf("condition %s is not in allow-list %v", conditionType, c.AllowList)
+	}
+
+	return nil
+}
+
+// QualifyType prefixes a condition type without a prefix with the prefix of the team.
+// The condition type is returned unchanged if no team is given, if it already has a prefix,
+// or if it is a regular expression.
+func (c *Config) QualifyType(team, conditionType string) (string, error) {
+	if team == "" {
+		return conditionType, nil
+	}
+
+	t, ok := c.Teams[team]
+	if !ok {
+		return "", fmt.Errorf("unknown team %s, must be one of %v", team, slices.Sorted(maps.Keys(c.Teams)))
+	}
+
+	if strings.Contains(conditionType, "/") {
+		return conditionType, nil
+	}
+
+	return t.Prefix + "/" + conditionType, nil
+}
+
+// CheckTeam checks that the team may use the condition type.
+// A team may only use condition types under its own prefix, and without a team
+// condition types under the prefix of any team may not be used. A condition type that may not be used
+// is reported with a RejectedError.
+func (c *Config) CheckTeam(team, conditionType string) error {
+	prefix := ""
+	if i := strings.Index(conditionType, "/"); i != -1 {
+		prefix = conditionType[:i]
+	}
+
+	if team != "" {
+		t, ok := c.Teams[team]
+		if !ok {
+			return fmt.Errorf("unknown team %s, must be one of %v", team, slices.Sorted(maps.Keys(c.Teams)))
+		}
+
+		if prefix != t.Prefix {
+			return rejectf("team %s may only use condition types prefixed with %s/, not %s", team, t.Prefix, conditionType)
+		}
+
+		return nil
+	}
+
+	for name, t := range c.Teams {
+		if prefix == t.Prefix {
+			return rejectf("condition %s belongs to team %s, use --team %s", conditionType, name, name)
+		}
+	}
+
+	return nil
+}
+
+// MessageLimit returns the maximum length of a condition message.
+func (c *Config) MessageLimit() int {
+	if c.MaxMessageLength <= 0 {
+		return DefaultMaxMessageLength
+	}
+
+	return c.MaxMessageLength
+}
+
+// IdentitySource returns where the identity prepended to messages comes from.
+func (c *Config) IdentitySource() string {
+	if c.WhoAmISource == "" {
+		return WhoAmISourceOS
+	}
+
+	return c.WhoAmISource
+}
+
+// IdentityFormat returns the text/template embedding the identity in the message.
+func (c *Config) IdentityFormat() string {
+	if c.WhoAmIFormat == "" {
+		return DefaultWhoAmIFormat
+	}
+
+	return c.WhoAmIFormat
+}
+
+// ConfirmationThreshold returns the number of nodes above which the user is asked to confirm a change.
+func (c *Config) ConfirmationThreshold() int {
+	if c.ConfirmThreshold <= 0 {
+		return DefaultConfirmThreshold
+	}
+
+	return c.ConfirmThreshold
+}
+
+// AuditLogPath returns the path of the audit log with a leading ~ expanded, or an empty string if it is disabled.
+func (c *Config) AuditLogPath() (string, error) {
+	if c.AuditLog == "" {
+		return "", nil
+	}
+
+	return homedir.Expand(c.AuditLog)
+}
+
+// AuditLogRotation returns the size in bytes after which the audit log is rotated and the number of rotated logs kept.
+func (c *Config) AuditLogRotation() (int64, int) {
+	maxSize := c.AuditLogMaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultAuditLogMaxSize
+	}
+
+	maxBackups := c.AuditLogMaxBackups
+	if maxBackups <= 0 {
+		maxBackups = DefaultAuditLogMaxBackups
+	}
+
+	return int64(maxSize) * 1024 * 1024, maxBackups
+}
+
+// Policy returns the policy of the condition type.
+// It returns the zero TypePolicy, which allows everything, if the condition type has no policy.
+func (c *Config) Policy(conditionType string) TypePolicy {
+	return c.Policies[conditionType]
+}
+
+// Protected reports whether the condition type is protected from modification.
+func (c *Config) Protected(conditionType string) bool {
+	protected := c.ProtectedTypes
+	if protected == nil {
+		protected = DefaultProtectedTypes
+	}
+
+	return slices.Contains(protected, conditionType)
+}
+
+// ClusterProtected reports whether the condition type is protected by a cluster policy, so it may not be modified
+// even with --force-protected.
+func (c *Config) ClusterProtected(conditionType string) bool {
+	return slices.Contains(c.clusterProtectedTypes, conditionType)
+}
+
+// Path resolves the path of the configuration file.
+// The override (the --config flag) takes precedence, followed by the CONDITIONER_CONFIG environment variable.
+// Otherwise $XDG_CONFIG_HOME/conditioner/config.yaml is used if it exists, followed by the legacy ~/.conditioner.json
+// if it exists. When neither exists the XDG location is returned, as that is where new configuration files are created.
+func Path(fs Filesystem, override string) (string, error) {
+	if override != "" {
+		return homedir.Expand(override)
+	}
+
+	if env := os.Getenv(EnvConfig); env != "" {
+		return homedir.Expand(env)
+	}
+
+	xdgPath, err := getXDGPath()
+	if err != nil {
+		return "", err
+	}
+
+	exists, err := Exists(fs, xdgPath)
+	if err != nil || exists {
+		return xdgPath, err
+	}
+
+	legacyPath, err := getPath()
+	if err != nil {
+		return "", err
+	}
+
+	exists, err = Exists(fs, legacyPath)
+	if err != nil || exists {
+		return legacyPath, err
+	}
+
+	return xdgPath, nil
+}
+
+// Load reads the configuration file resolved by Path.
+// If no configuration file exists in the default locations the built-in Default configuration is returned,
+// whereas a file given explicitly through the override or CONDITIONER_CONFIG must exist.
+func Load(fs Filesystem, override string) (*Config, error) {
+	path, err := Path(fs, override)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Read(fs, path)
+	if errors.Is(err, os.ErrNotExist) && override == "" && os.Getenv(EnvConfig) == "" {
+		return Default(), nil
 	}
 
+	return config, err
+}
+
+// Default returns the built-in configuration used when no configuration file exists.
+func Default() *Config {
+	return &Config{
+		Version:        SchemaVersion,
+		WhoAmI:         false,
+		AllowList:      []string{},
+		ProtectedTypes: slices.Clone(DefaultProtectedTypes),
+	}
+}
+
+// Exists checks if the configuration file exists.
+// It returns a boolean indicating the existence of the file and any error encountered.
+func Exists(fs Filesystem, path string) (bool, error) {
 	if _, err := fs.Stat(path); err == nil {
 		return true, nil
 	} else if errors.Is(err, os.ErrNotExist) {
@@ -37,48 +414,82 @@ func Exists(fs Filesystem) (bool, error) {
 	}
 }
 
-// Write writes the default configuration to the configuration file.
+// Write writes the configuration to the configuration file at path, creating its directory if needed.
+// The file is written as YAML if path has a .yaml or .yml extension, and as JSON otherwise.
 // It returns any error encountered during the operation.
-func Write() error {
-	conf := &Config{
-		WhoAmI:    false,
-		AllowList: []string{},
-	}
-
+func Write(fs Filesystem, path string, conf *Config) error {
 	confJson, err := json.MarshalIndent(conf, "", "\t")
 	if err != nil {
 		return err
 	}
 
-	fileName, err := getPath()
-	if err != nil {
+	if isYAML(path) {
+		confJson, err = yaml.JSONToYAML(confJson)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(fileName, confJson, 0o644)
+	return fs.WriteFile(path, confJson, 0o644)
 }
 
-// Read is a placeholder function for reading the configuration file.
-func Read(fs Filesystem) (*Config, error) {
-	path, err := getPath()
-	if err != nil {
-		return nil, err
-	}
-
+// Read reads the configuration file at path and strictly decodes it, see Parse.
+func Read(fs Filesystem, path string) (*Config, error) {
 	byteConfig, err := fs.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	config := &Config{}
-	if err := json.Unmarshal(byteConfig, config); err != nil {
-		return nil, err
+	return Parse(byteConfig, path)
+}
+
+// matchAny reports whether the condition type matches any of the entries.
+func matchAny(entries []string, conditionType string) (bool, error) {
+	for _, entry := range entries {
+		if err := pattern.Validate(entry); err != nil {
+			return false, err
+		}
+
+		ok, err := pattern.Match(entry, conditionType)
+		if err != nil {
+			return false, err
+		}
+
+		if ok {
+			return true, nil
+		}
 	}
-	return config, nil
+
+	return false, nil
 }
 
-// getPath expands the configuration file name to its full path.
+// getPath expands the legacy configuration file name to its full path.
 // It returns the full path and any error encountered.
 func getPath() (string, error) {
 	return homedir.Expand(configName)
 }
+
+// getXDGPath returns the path of the configuration file in the XDG config directory.
+// $XDG_CONFIG_HOME defaults to ~/.config when it is unset, as required by the XDG Base Directory Specification.
+func getXDGPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, xdgConfigName), nil
+	}
+
+	dir, err := homedir.Expand("~/.config")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, xdgConfigName), nil
+}
+
+// isYAML reports whether the configuration file at path is a YAML file.
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
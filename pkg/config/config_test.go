@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/devbytes-cloud/conditioner/pkg/mocks"
@@ -19,7 +21,7 @@ func TestExists(t *testing.T) {
 	t.Run("File Exists", func(t *testing.T) {
 		mockFS.On("Stat", path).Return(nil, nil).Once()
 
-		exists, err := Exists(mockFS)
+		exists, err := Exists(mockFS, path)
 		assert.NoError(t, err)
 		assert.True(t, exists)
 	})
@@ -28,7 +30,7 @@ func TestExists(t *testing.T) {
 		expectedError := errors.New("random error")
 		mockFS.On("Stat", path).Return(nil, expectedError).Once()
 
-		exists, err := Exists(mockFS)
+		exists, err := Exists(mockFS, path)
 		assert.Error(t, err, expectedError)
 		assert.False(t, exists)
 	})
@@ -36,7 +38,7 @@ func TestExists(t *testing.T) {
 	t.Run("File does not exist", func(t *testing.T) {
 		mockFS.On("Stat", path).Return(nil, fmt.Errorf("%w", os.ErrNotExist)).Once()
 
-		exists, err := Exists(mockFS)
+		exists, err := Exists(mockFS, path)
 		assert.Nil(t, err)
 		assert.False(t, exists)
 	})
@@ -52,7 +54,7 @@ func TestRead(t *testing.T) {
 		expectedError := errors.New("not found")
 		mockFS.On("ReadFile", path).Return(nil, expectedError).Once()
 
-		cfg, err := Read(mockFS)
+		cfg, err := Read(mockFS, path)
 
 		assert.Error(t, err, expectedError)
 		assert.Nil(t, cfg)
@@ -61,7 +63,7 @@ func TestRead(t *testing.T) {
 	t.Run("Error: json unmarshall", func(t *testing.T) {
 		mockFS.On("ReadFile", path).Return(nil, nil).Once()
 
-		cfg, err := Read(mockFS)
+		cfg, err := Read(mockFS, path)
 
 		assert.Error(t, err)
 		assert.Nil(t, cfg)
@@ -69,6 +71,7 @@ func TestRead(t *testing.T) {
 
 	t.Run("Success: json unmarshall", func(t *testing.T) {
 		expectedConfig := &Config{
+			Version:   SchemaVersion,
 			WhoAmI:    false,
 			AllowList: []string{"unit-test"},
 		}
@@ -78,9 +81,285 @@ func TestRead(t *testing.T) {
 
 		mockFS.On("ReadFile", path).Return(byteArray, nil).Once()
 
-		cfg, err := Read(mockFS)
+		cfg, err := Read(mockFS, path)
 
 		assert.NoError(t, err)
 		assert.Equal(t, cfg, expectedConfig)
 	})
 }
+
+func TestReadYAML(t *testing.T) {
+	mockFS := new(mocks.MockFilesystem)
+	path := "/etc/conditioner/config.yaml"
+
+	t.Run("Error: yaml unmarshall", func(t *testing.T) {
+		mockFS.On("ReadFile", path).Return([]byte("allow-list: [unterminated"), nil).Once()
+
+		cfg, err := Read(mockFS, path)
+
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("Success: yaml unmarshall", func(t *testing.T) {
+		mockFS.On("ReadFile", path).Return([]byte("prepend-whoami: true\nallow-list:\n  - unit-test\n"), nil).Once()
+
+		cfg, err := Read(mockFS, path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Config{Version: SchemaVersion, WhoAmI: true, AllowList: []string{"unit-test"}}, cfg)
+	})
+}
+
+func TestPath(t *testing.T) {
+	xdgHome := "/xdg"
+	xdgPath := filepath.Join(xdgHome, xdgConfigName)
+	legacyPath, err := getPath()
+	assert.NoError(t, err)
+
+	t.Run("Flag override", func(t *testing.T) {
+		t.Setenv(EnvConfig, "/env/config.yaml")
+		mockFS := new(mocks.MockFilesystem)
+
+		path, err := Path(mockFS, "/flag/config.json")
+		assert.NoError(t, err)
+		assert.Equal(t, "/flag/config.json", path)
+	})
+
+	t.Run("Environment override", func(t *testing.T) {
+		t.Setenv(EnvConfig, "/env/config.yaml")
+		mockFS := new(mocks.MockFilesystem)
+
+		path, err := Path(mockFS, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "/env/config.yaml", path)
+	})
+
+	t.Run("XDG config exists", func(t *testing.T) {
+		t.Setenv(EnvConfig, "")
+		t.Setenv("XDG_CONFIG_HOME", xdgHome)
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("Stat", xdgPath).Return(nil, nil).Once()
+
+		path, err := Path(mockFS, "")
+		assert.NoError(t, err)
+		assert.Equal(t, xdgPath, path)
+	})
+
+	t.Run("Legacy fallback", func(t *testing.T) {
+		t.Setenv(EnvConfig, "")
+		t.Setenv("XDG_CONFIG_HOME", xdgHome)
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("Stat", xdgPath).Return(nil, os.ErrNotExist).Once()
+		mockFS.On("Stat", legacyPath).Return(nil, nil).Once()
+
+		path, err := Path(mockFS, "")
+		assert.NoError(t, err)
+		assert.Equal(t, legacyPath, path)
+	})
+
+	t.Run("No config defaults to XDG", func(t *testing.T) {
+		t.Setenv(EnvConfig, "")
+		t.Setenv("XDG_CONFIG_HOME", xdgHome)
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("Stat", xdgPath).Return(nil, os.ErrNotExist).Once()
+		mockFS.On("Stat", legacyPath).Return(nil, os.ErrNotExist).Once()
+
+		path, err := Path(mockFS, "")
+		assert.NoError(t, err)
+		assert.Equal(t, xdgPath, path)
+	})
+
+	t.Run("Stat error", func(t *testing.T) {
+		t.Setenv(EnvConfig, "")
+		t.Setenv("XDG_CONFIG_HOME", xdgHome)
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("Stat", xdgPath).Return(nil, errors.New("permission denied")).Once()
+
+		_, err := Path(mockFS, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	xdgHome := "/xdg"
+	xdgPath := filepath.Join(xdgHome, xdgConfigName)
+	legacyPath, err := getPath()
+	assert.NoError(t, err)
+
+	t.Run("Defaults without a config file", func(t *testing.T) {
+		t.Setenv(EnvConfig, "")
+		t.Setenv("XDG_CONFIG_HOME", xdgHome)
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("Stat", xdgPath).Return(nil, os.ErrNotExist).Once()
+		mockFS.On("Stat", legacyPath).Return(nil, os.ErrNotExist).Once()
+		mockFS.On("ReadFile", xdgPath).Return(nil, os.ErrNotExist).Once()
+
+		cfg, err := Load(mockFS, "")
+		assert.NoError(t, err)
+		assert.Equal(t, Default(), cfg)
+	})
+
+	t.Run("Error: explicit config file missing", func(t *testing.T) {
+		mockFS := new(mocks.MockFilesystem)
+		mockFS.On("ReadFile", "/missing.yaml").Return(nil, os.ErrNotExist).Once()
+
+		cfg, err := Load(mockFS, "/missing.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("Success: yaml", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().MkdirAll("/xdg/conditioner", os.FileMode(0o755)).Return(nil).Once()
+		mockFS.EXPECT().WriteFile("/xdg/conditioner/config.yaml", []byte("allow-list: []\nprepend-whoami: false\nprotected-types:\n- Ready\n- MemoryPressure\n- DiskPressure\n- PIDPressure\n- NetworkUnavailable\nversion: 1\n"), os.FileMode(0o644)).Return(nil).Once()
+
+		assert.NoError(t, Write(mockFS, "/xdg/conditioner/config.yaml", Default()))
+	})
+
+	t.Run("Success: json", func(t *testing.T) {
+		conf := &Config{AllowList: []string{"unit-test"}}
+		expected, err := json.MarshalIndent(conf, "", "\t")
+		assert.NoError(t, err)
+
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().MkdirAll("/home/user", os.FileMode(0o755)).Return(nil).Once()
+		mockFS.EXPECT().WriteFile("/home/user/.conditioner.json", expected, os.FileMode(0o644)).Return(nil).Once()
+
+		assert.NoError(t, Write(mockFS, "/home/user/.conditioner.json", conf))
+	})
+
+	t.Run("Error: read-only directory", func(t *testing.T) {
+		mockFS := mocks.NewMockFilesystem(t)
+		mockFS.EXPECT().MkdirAll("/xdg/conditioner", os.FileMode(0o755)).Return(os.ErrPermission).Once()
+
+		assert.ErrorIs(t, Write(mockFS, "/xdg/conditioner/config.yaml", Default()), os.ErrPermission)
+	})
+}
+
+func TestProtected(t *testing.T) {
+	t.Run("Defaults to kubelet conditions", func(t *testing.T) {
+		cfg := &Config{}
+		assert.True(t, cfg.Protected("Ready"))
+		assert.True(t, cfg.Protected("NetworkUnavailable"))
+		assert.False(t, cfg.Protected("NodeMaintenance"))
+	})
+
+	t.Run("Configured list replaces defaults", func(t *testing.T) {
+		cfg := &Config{ProtectedTypes: []string{"NodeMaintenance"}}
+		assert.True(t, cfg.Protected("NodeMaintenance"))
+		assert.False(t, cfg.Protected("Ready"))
+	})
+
+	t.Run("Empty list disables protection", func(t *testing.T) {
+		cfg := &Config{ProtectedTypes: []string{}}
+		assert.False(t, cfg.Protected("Ready"))
+	})
+}
+
+func TestMessageLimit(t *testing.T) {
+	assert.Equal(t, DefaultMaxMessageLength, (&Config{}).MessageLimit())
+	assert.Equal(t, 256, (&Config{MaxMessageLength: 256}).MessageLimit())
+}
+
+func TestCheckType(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		in      string
+		wantErr string
+	}{
+		{"Empty lists allow everything", Config{}, "anything", ""},
+		{"Exact allow-list entry", Config{AllowList: []string{"NodeMaintenance"}}, "NodeMaintenance", ""},
+		{"Not in allow-list", Config{AllowList: []string{"NodeMaintenance"}}, "Other", "condition Other is not in allow-list [NodeMaintenance]"},
+		{"Glob allow-list entry", Config{AllowList: []string{"team-a.example.com/*"}}, "team-a.example.com/Healthy", ""},
+		{"Glob does not cross prefixes", Config{AllowList: []string{"team-a.example.com/*"}}, "team-b.example.com/Healthy", "condition team-b.example.com/Healthy is not in allow-list [team-a.example.com/*]"},
+		{"Regex allow-list entry", Config{AllowList: []string{"/^team-(a|b)\\./"}}, "team-b.example.com/Healthy", ""},
+		{"Deny-list", Config{DenyList: []string{"Ready"}}, "Ready", "condition Ready is in deny-list [Ready]"},
+		{"Deny-list takes precedence", Config{AllowList: []string{"team-a.example.com/*"}, DenyList: []string{"*/Legacy*"}}, "team-a.example.com/LegacyProbe", "condition team-a.example.com/LegacyProbe is in deny-list [*/Legacy*]"},
+		{"Glob deny-list entry matches prefixed types", Config{DenyList: []string{"*Legacy*"}}, "team-a.example.com/LegacyProbe", "condition team-a.example.com/LegacyProbe is in deny-list [*Legacy*]"},
+		{"Glob star allows prefixed types", Config{AllowList: []string{"*"}}, "team-a.example.com/Healthy", ""},
+		{"Invalid allow-list entry", Config{AllowList: []string{"[broken"}}, "Ready", "allow-list: invalid glob [broken: syntax error in pattern"},
+		{"Invalid deny-list entry", Config{DenyList: []string{"/(broken/"}}, "Ready", "deny-list: invalid regular expression /(broken/: error parsing regexp: missing closing ): `(broken`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.CheckType(tt.in)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, strings.HasPrefix(tt.wantErr, "condition "), IsRejected(err))
+		})
+	}
+}
+
+func TestQualifyType(t *testing.T) {
+	cfg := &Config{Teams: map[string]Team{"storage": {Prefix: "storage.example.com"}}}
+
+	tests := []struct {
+		name    string
+		team    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{"No team", "", "Healthy", "Healthy", ""},
+		{"Unprefixed type", "storage", "Healthy", "storage.example.com/Healthy", ""},
+		{"Prefixed type", "storage", "network.example.com/Healthy", "network.example.com/Healthy", ""},
+		{"Glob", "storage", "*", "storage.example.com/*", ""},
+		{"Regex", "storage", "/Healthy$/", "/Healthy$/", ""},
+		{"Unknown team", "compute", "Healthy", "", "unknown team compute, must be one of [storage]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.QualifyType(tt.team, tt.in)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckTeam(t *testing.T) {
+	cfg := &Config{Teams: map[string]Team{
+		"storage": {Prefix: "storage.example.com"},
+		"network": {Prefix: "network.example.com"},
+	}}
+
+	tests := []struct {
+		name    string
+		team    string
+		in      string
+		wantErr string
+	}{
+		{"Own prefix", "storage", "storage.example.com/Healthy", ""},
+		{"Other team's prefix", "storage", "network.example.com/Healthy", "team storage may only use condition types prefixed with storage.example.com/, not network.example.com/Healthy"},
+		{"Unprefixed type with a team", "storage", "Healthy", "team storage may only use condition types prefixed with storage.example.com/, not Healthy"},
+		{"No team, unowned type", "", "example.com/Healthy", ""},
+		{"No team, unprefixed type", "", "Healthy", ""},
+		{"No team, owned type", "", "network.example.com/Healthy", "condition network.example.com/Healthy belongs to team network, use --team network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cfg.CheckTeam(tt.team, tt.in)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
+)
+
+// SchemaVersion is the version of the configuration schema written by this release.
+// Files without a version predate versioning and are read as version 1.
+const SchemaVersion int = 1
+
+// unknownFieldRegexp extracts the field name from the error encoding/json returns for unknown fields.
+var unknownFieldRegexp = regexp.MustCompile(`unknown field "([^"]*)"`)
+
+// Parse strictly decodes a configuration file and migrates it to the current SchemaVersion.
+// Unknown fields are rejected, so typos such as "allowlist" are reported instead of silently disabling a setting,
+// and errors carry the line and column of the problem where it can be determined. The path selects the format.
+func Parse(data []byte, path string) (*Config, error) {
+	config := &Config{}
+
+	var err error
+	if isYAML(path) {
+		err = yaml.UnmarshalStrict(data, config)
+	} else {
+		config, err = decodeJSONStrict(data)
+	}
+
+	if err != nil {
+		if line, column, ok := locate(data, err, !isYAML(path)); ok {
+			return nil, fmt.Errorf("%s:%d:%d: %w", path, line, column, err)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := migrate(config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// decodeJSONStrict decodes a JSON configuration, rejecting unknown fields.
+func decodeJSONStrict(data []byte) (*Config, error) {
+	config := &Config{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// migrate upgrades a decoded configuration to the current SchemaVersion.
+// A configuration written by a newer release is rejected rather than misread.
+func migrate(config *Config) error {
+	if config.Version > SchemaVersion {
+		return fmt.Errorf("configuration version %d is newer than the supported version %d, upgrade conditioner", config.Version, SchemaVersion)
+	}
+
+	if config.Version < 0 {
+		return fmt.Errorf("configuration version %d is invalid", config.Version)
+	}
+
+	// Unversioned files use the version 1 schema.
+	if config.Version == 0 {
+		config.Version = 1
+	}
+
+	return nil
+}
+
+// locate returns the 1-based line and column of the decoding error in data. JSON syntax and type errors carry
+// the offset just past the offending byte, unknown fields and YAML type errors are located by searching for the key.
+// The YAML parser already reports the line of syntax errors in its message, so they are not located again.
+func locate(data []byte, err error, isJSON bool) (int, int, bool) {
+	var syntaxErr *json.SyntaxError
+	if isJSON && errors.As(err, &syntaxErr) {
+		line, column := position(data, syntaxErr.Offset-1)
+		return line, column, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if isJSON {
+			line, column := position(data, typeErr.Offset-1)
+			return line, column, true
+		}
+
+		fields := strings.Split(typeErr.Field, ".")
+		return findKey(data, fields[len(fields)-1])
+	}
+
+	if match := unknownFieldRegexp.FindStringSubmatch(err.Error()); match != nil {
+		return findKey(data, match[1])
+	}
+
+	return 0, 0, false
+}
+
+// position converts a byte offset in data to a 1-based line and column.
+func position(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
+
+// findKey returns the line and column of the first mapping key named key in the YAML or JSON document.
+func findKey(data []byte, key string) (int, int, bool) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err != nil {
+		return 0, 0, false
+	}
+
+	node := findKeyNode(&root, key)
+	if node == nil {
+		return 0, 0, false
+	}
+
+	return node.Line, node.Column, true
+}
+
+// findKeyNode searches the node tree depth-first for a mapping key named key.
+func findKeyNode(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i]
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		if found := findKeyNode(child, key); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
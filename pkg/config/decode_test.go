@@ -0,0 +1,98 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "Success: json",
+			path: "config.json",
+			data: `{"allow-list": ["unit-test"]}`,
+		},
+		{
+			name: "Success: yaml",
+			path: "config.yaml",
+			data: "version: 1\nallow-list: [unit-test]\n",
+		},
+		{
+			name:    "Error: unknown json field",
+			path:    "config.json",
+			data:    "{\n  \"prepend-whoami\": true,\n  \"allowlist\": [\"unit-test\"]\n}",
+			wantErr: `config.json:3:3: json: unknown field "allowlist"`,
+		},
+		{
+			name:    "Error: unknown nested yaml field",
+			path:    "config.yaml",
+			data:    "allow-list: [unit-test]\npolicies:\n  NodeMaintenance:\n    reason: [MaintenanceScheduled]\n",
+			wantErr: `config.yaml:4:5: error unmarshaling JSON: while decoding JSON: json: unknown field "reason"`,
+		},
+		{
+			name:    "Error: json syntax",
+			path:    "config.json",
+			data:    "{\n  \"allow-list\": [\"unit-test\",]\n}",
+			wantErr: "config.json:2:30: invalid character ']' looking for beginning of value",
+		},
+		{
+			name:    "Error: json type",
+			path:    "config.json",
+			data:    "{\n  \"prepend-whoami\": \"yes\"\n}",
+			wantErr: "config.json:2:25: json: cannot unmarshal string into Go struct field Config.prepend-whoami of type bool",
+		},
+		{
+			name:    "Error: yaml type",
+			path:    "config.yaml",
+			data:    "allow-list: []\nprepend-whoami: yes please\n",
+			wantErr: "config.yaml:2:1: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go struct field .prepend-whoami of type bool",
+		},
+		{
+			name:    "Error: yaml syntax",
+			path:    "config.yaml",
+			data:    "allow-list: [unterminated\n",
+			wantErr: "config.yaml: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'",
+		},
+		{
+			name:    "Error: newer version",
+			path:    "config.yaml",
+			data:    "version: 2\n",
+			wantErr: "config.yaml: configuration version 2 is newer than the supported version 1, upgrade conditioner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data), tt.path)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, cfg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, SchemaVersion, cfg.Version)
+			assert.Equal(t, []string{"unit-test"}, cfg.AllowList)
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	data := []byte("ab\ncd\n")
+
+	line, column := position(data, 0)
+	assert.Equal(t, []int{1, 1}, []int{line, column})
+
+	line, column = position(data, 4)
+	assert.Equal(t, []int{2, 2}, []int{line, column})
+
+	line, column = position(data, 100)
+	assert.Equal(t, []int{3, 1}, []int{line, column})
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Set returns a copy of the configuration with the value at key replaced.
+// The key is a dot separated path of field names (e.g. policies.NodeMaintenance.require-message),
+// a literal dot in a field name is escaped with a backslash. The value is parsed as YAML, so
+// "true", "10" and "[a, b]" become a boolean, a number and a list.
+func Set(conf *Config, key, value string) (*Config, error) {
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("parsing value %q: %w", value, err)
+	}
+
+	return edit(conf, key, func(parent map[string]interface{}, field string) error {
+		parent[field] = parsed
+		return nil
+	})
+}
+
+// Unset returns a copy of the configuration with the value at key removed, see Set for the key syntax.
+func Unset(conf *Config, key string) (*Config, error) {
+	return edit(conf, key, func(parent map[string]interface{}, field string) error {
+		if _, ok := parent[field]; !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+
+		delete(parent, field)
+		return nil
+	})
+}
+
+// edit applies the change to the field at key of the configuration's JSON representation
+// and strictly decodes the result, so unknown keys and wrongly typed values are rejected.
+func edit(conf *Config, key string, change func(parent map[string]interface{}, field string) error) (*Config, error) {
+	fields := splitKey(key)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+
+	raw, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	parent := doc
+	for _, field := range fields[:len(fields)-1] {
+		child, ok := parent[field].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			parent[field] = child
+		}
+
+		parent = child
+	}
+
+	if err := change(parent, fields[len(fields)-1]); err != nil {
+		return nil, err
+	}
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := decodeJSONStrict(raw)
+	if err != nil {
+		return nil, fmt.Errorf("setting %s: %w", key, err)
+	}
+
+	return updated, nil
+}
+
+// splitKey splits a key on unescaped dots.
+func splitKey(key string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '.':
+			field.WriteByte('.')
+			i++
+		case key[i] == '.':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(key[i])
+		}
+	}
+
+	if key != "" {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    func(*Config) bool
+		wantErr string
+	}{
+		{"Boolean", "prepend-whoami", "true", func(c *Config) bool { return c.WhoAmI }, ""},
+		{"List", "allow-list", "[a, b]", func(c *Config) bool { return assert.ObjectsAreEqual([]string{"a", "b"}, c.AllowList) }, ""},
+		{"Number", "max-message-length", "256", func(c *Config) bool { return c.MaxMessageLength == 256 }, ""},
+		{"Nested escaped key", `policies.storage\.example\.com/Healthy.require-message`, "true", func(c *Config) bool {
+			return c.Policies["storage.example.com/Healthy"].RequireMessage
+		}, ""},
+		{"Unknown key", "allowlist", "[a]", nil, `setting allowlist: json: unknown field "allowlist"`},
+		{"Wrong type", "prepend-whoami", "[a]", nil, "setting prepend-whoami: json: cannot unmarshal array"},
+		{"Empty key", "", "true", nil, "key must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Set(Default(), tt.key, tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want(cfg))
+		})
+	}
+}
+
+func TestUnset(t *testing.T) {
+	cfg, err := Unset(&Config{AllowList: []string{"a"}, DefaultTeam: "storage"}, "default-team")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.DefaultTeam)
+	assert.Equal(t, []string{"a"}, cfg.AllowList)
+
+	_, err = Unset(&Config{}, "policies.NodeMaintenance")
+	assert.EqualError(t, err, "policies.NodeMaintenance is not set")
+}
+
+func TestSplitKey(t *testing.T) {
+	assert.Equal(t, []string{"allow-list"}, splitKey("allow-list"))
+	assert.Equal(t, []string{"policies", "NodeMaintenance", "reasons"}, splitKey("policies.NodeMaintenance.reasons"))
+	assert.Equal(t, []string{"policies", "a.example.com/Healthy", "reasons"}, splitKey(`policies.a\.example\.com/Healthy.reasons`))
+	assert.Empty(t, splitKey(""))
+}
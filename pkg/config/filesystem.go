@@ -17,6 +17,10 @@ type Filesystem interface {
 	// If the file does not exist, WriteFile creates it with permissions `perm`;
 	// otherwise WriteFile truncates it before writing.
 	WriteFile(name string, data []byte, perm os.FileMode) error
+
+	// MkdirAll creates the directory named `path`, along with any necessary parents.
+	// If `path` is already a directory, MkdirAll does nothing.
+	MkdirAll(path string, perm os.FileMode) error
 }
 
 // FS implements the Filesystem interface using the os package.
@@ -40,3 +44,9 @@ func (f FS) Stat(name string) (os.FileInfo, error) {
 func (f FS) WriteFile(name string, data []byte, perm os.FileMode) error {
 	return os.WriteFile(name, data, perm)
 }
+
+// MkdirAll creates the directory named `path`, along with any necessary parents.
+// It uses os.MkdirAll to perform the operation, returning any errors encountered.
+func (f FS) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
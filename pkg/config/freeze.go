@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/cron"
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
+)
+
+// Freeze is a change freeze during which conditions may only be changed with --override-freeze.
+// It is either a date range, from Start to End, or a recurring window opening whenever Schedule
+// matches and lasting for Duration.
+type Freeze struct {
+	// Name identifies the freeze in error messages, e.g. end-of-year.
+	Name string `json:"name,omitempty"`
+	// Start is the RFC 3339 time the freeze starts, e.g. 2026-12-20T00:00:00Z.
+	Start string `json:"start,omitempty"`
+	// End is the RFC 3339 time the freeze ends.
+	End string `json:"end,omitempty"`
+	// Schedule is a five field cron expression opening a recurring freeze, e.g. "0 16 * * 5" for Friday 16:00.
+	Schedule string `json:"schedule,omitempty"`
+	// Duration is how long a recurring freeze lasts once opened, e.g. 64h.
+	Duration string `json:"duration,omitempty"`
+	// TimeZone is the IANA time zone the schedule is evaluated in. When unset the schedule is evaluated in UTC.
+	TimeZone string `json:"time-zone,omitempty"`
+	// Types limits the freeze to the condition types, globs or regular expressions wrapped in slashes.
+	// An empty list freezes every condition type.
+	Types []string `json:"types,omitempty"`
+	// Contexts limits the freeze to the kubeconfig contexts, globs or regular expressions wrapped in slashes.
+	// An empty list freezes every context.
+	Contexts []string `json:"contexts,omitempty"`
+}
+
+// String returns the name of the freeze, or a description of its window when it has no name.
+func (f Freeze) String() string {
+	switch {
+	case f.Name != "":
+		return f.Name
+	case f.Schedule != "":
+		return fmt.Sprintf("%q for %s", f.Schedule, f.Duration)
+	default:
+		return fmt.Sprintf("%s to %s", f.Start, f.End)
+	}
+}
+
+// ActiveUntil reports whether the freeze is in effect at now and, if so, when it ends.
+func (f Freeze) ActiveUntil(now time.Time) (time.Time, bool, error) {
+	if f.Schedule == "" {
+		start, err := time.Parse(time.RFC3339, f.Start)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+
+		end, err := time.Parse(time.RFC3339, f.End)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+
+		return end, !now.Before(start) && now.Before(end), nil
+	}
+
+	schedule, err := cron.Parse(f.Schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	duration, err := time.ParseDuration(f.Duration)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	location, err := time.LoadLocation(f.TimeZone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	// The most recent opening within the duration keeps the freeze in effect the longest.
+	now = now.In(location)
+	for start := now.Truncate(time.Minute); now.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return start.Add(duration), true, nil
+		}
+	}
+
+	return time.Time{}, false, nil
+}
+
+// Covers reports whether the freeze applies to the condition type in the kubeconfig context.
+// An empty condition type stands for changes that are not limited to a single type, such as removing
+// every custom condition, which every freeze of the context covers.
+func (f Freeze) Covers(context, conditionType string) (bool, error) {
+	if len(f.Contexts) > 0 {
+		ok, err := matchAny(f.Contexts, context)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if len(f.Types) == 0 || conditionType == "" {
+		return true, nil
+	}
+
+	return matchAny(f.Types, conditionType)
+}
+
+// ActiveFreeze returns the freeze covering the condition type in the kubeconfig context at now and when it ends,
+// or nil if no freeze is in effect. See Freeze.Covers for the meaning of an empty condition type.
+func (c *Config) ActiveFreeze(now time.Time, context, conditionType string) (*Freeze, time.Time, error) {
+	for i, freeze := range c.Freezes {
+		covers, err := freeze.Covers(context, conditionType)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		if !covers {
+			continue
+		}
+
+		until, active, err := freeze.ActiveUntil(now)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("freeze %s: %w", freeze, err)
+		}
+
+		if active {
+			return &c.Freezes[i], until, nil
+		}
+	}
+
+	return nil, time.Time{}, nil
+}
+
+// validate checks the values of the freeze, prefixing every problem with the key of the freeze.
+func (f Freeze) validate(key string) []error {
+	var errs []error
+
+	switch {
+	case f.Schedule != "" && (f.Start != "" || f.End != ""):
+		errs = append(errs, fmt.Errorf("%s: either start and end or schedule and duration must be set, not both", key))
+	case f.Schedule != "":
+		if _, err := cron.Parse(f.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("%s.schedule: %w", key, err))
+		}
+
+		if duration, err := time.ParseDuration(f.Duration); err != nil {
+			errs = append(errs, fmt.Errorf("%s.duration: %w", key, err))
+		} else if duration <= 0 {
+			errs = append(errs, fmt.Errorf("%s.duration: must be greater than zero", key))
+		}
+
+		if _, err := time.LoadLocation(f.TimeZone); err != nil {
+			errs = append(errs, fmt.Errorf("%s.time-zone: %w", key, err))
+		}
+	default:
+		start, startErr := time.Parse(time.RFC3339, f.Start)
+		if startErr != nil {
+			errs = append(errs, fmt.Errorf("%s.start: %w", key, startErr))
+		}
+
+		end, endErr := time.Parse(time.RFC3339, f.End)
+		if endErr != nil {
+			errs = append(errs, fmt.Errorf("%s.end: %w", key, endErr))
+		}
+
+		if startErr == nil && endErr == nil && !end.After(start) {
+			errs = append(errs, fmt.Errorf("%s.end: must be after start", key))
+		}
+
+		if f.Duration != "" || f.TimeZone != "" {
+			errs = append(errs, fmt.Errorf("%s: duration and time-zone can only be used with schedule", key))
+		}
+	}
+
+	for _, entry := range f.Types {
+		if err := pattern.Validate(entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s.types: %w", key, err))
+		}
+	}
+
+	for _, entry := range f.Contexts {
+		if err := pattern.Validate(entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s.contexts: %w", key, err))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeActiveUntil(t *testing.T) {
+	// 2026-12-25 is a Friday.
+	now := time.Date(2026, time.December, 25, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		freeze        Freeze
+		expectedUntil time.Time
+		expectedOK    bool
+	}{
+		{
+			name:          "Date range in effect",
+			freeze:        Freeze{Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z"},
+			expectedUntil: time.Date(2027, time.January, 4, 0, 0, 0, 0, time.UTC),
+			expectedOK:    true,
+		},
+		{
+			name:   "Date range over",
+			freeze: Freeze{Start: "2026-12-01T00:00:00Z", End: "2026-12-25T18:00:00Z"},
+		},
+		{
+			name:          "Schedule in effect",
+			freeze:        Freeze{Schedule: "0 16 * * 5", Duration: "64h"},
+			expectedUntil: time.Date(2026, time.December, 28, 8, 0, 0, 0, time.UTC),
+			expectedOK:    true,
+		},
+		{
+			name:          "Schedule in a time zone",
+			freeze:        Freeze{Schedule: "0 12 * * 5", Duration: "8h", TimeZone: "America/New_York"},
+			expectedUntil: time.Date(2026, time.December, 26, 1, 0, 0, 0, time.UTC),
+			expectedOK:    true,
+		},
+		{
+			name:   "Schedule not in effect",
+			freeze: Freeze{Schedule: "0 16 * * 5", Duration: "1h"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, ok, err := tt.freeze.ActiveUntil(now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOK, ok)
+			if tt.expectedOK {
+				assert.True(t, tt.expectedUntil.Equal(until), "expected %s, got %s", tt.expectedUntil, until)
+			}
+		})
+	}
+}
+
+func TestActiveFreeze(t *testing.T) {
+	now := time.Date(2026, time.December, 25, 18, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		Freezes: []Freeze{
+			{Name: "over", Start: "2026-11-01T00:00:00Z", End: "2026-11-02T00:00:00Z"},
+			{Name: "maintenance", Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z", Types: []string{"NodeMaintenance"}, Contexts: []string{"prod-*"}},
+		},
+	}
+
+	t.Run("Covered", func(t *testing.T) {
+		freeze, until, err := cfg.ActiveFreeze(now, "prod-eu", "NodeMaintenance")
+		require.NoError(t, err)
+		require.NotNil(t, freeze)
+		assert.Equal(t, "maintenance", freeze.Name)
+		assert.Equal(t, time.Date(2027, time.January, 4, 0, 0, 0, 0, time.UTC), until)
+	})
+
+	t.Run("Every type", func(t *testing.T) {
+		freeze, _, err := cfg.ActiveFreeze(now, "prod-eu", "")
+		require.NoError(t, err)
+		assert.NotNil(t, freeze)
+	})
+
+	t.Run("Other type", func(t *testing.T) {
+		freeze, _, err := cfg.ActiveFreeze(now, "prod-eu", "UnderInvestigation")
+		require.NoError(t, err)
+		assert.Nil(t, freeze)
+	})
+
+	t.Run("Other context", func(t *testing.T) {
+		freeze, _, err := cfg.ActiveFreeze(now, "staging", "NodeMaintenance")
+		require.NoError(t, err)
+		assert.Nil(t, freeze)
+	})
+}
+
+func TestFreezeString(t *testing.T) {
+	assert.Equal(t, "end-of-year", Freeze{Name: "end-of-year", Schedule: "0 16 * * 5"}.String())
+	assert.Equal(t, `"0 16 * * 5" for 64h`, Freeze{Schedule: "0 16 * * 5", Duration: "64h"}.String())
+	assert.Equal(t, "2026-12-20T00:00:00Z to 2027-01-04T00:00:00Z", Freeze{Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z"}.String())
+}
+
+func TestValidateFreezes(t *testing.T) {
+	cfg := &Config{
+		Freezes: []Freeze{
+			{Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z", Types: []string{"example.com/*"}, Contexts: []string{"/^prod-/"}},
+			{Schedule: "0 16 * * 5", Duration: "64h", TimeZone: "Europe/Amsterdam"},
+			{Start: "2026-12-20", End: "2026-12-19T00:00:00Z"},
+			{Start: "2026-12-20T00:00:00Z", End: "2026-12-19T00:00:00Z", Duration: "1h"},
+			{Schedule: "0 16 * *", Duration: "0s", TimeZone: "Mars/Olympus", Types: []string{"[broken"}, Contexts: []string{"/(broken/"}},
+			{Schedule: "0 16 * * 5", Start: "2026-12-20T00:00:00Z"},
+		},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "freezes[0]")
+	assert.NotContains(t, err.Error(), "freezes[1]")
+	assert.ErrorContains(t, err, `freezes[2].start: parsing time "2026-12-20"`)
+	assert.ErrorContains(t, err, "freezes[3].end: must be after start")
+	assert.ErrorContains(t, err, "freezes[3]: duration and time-zone can only be used with schedule")
+	assert.ErrorContains(t, err, `freezes[4].schedule: invalid cron expression "0 16 * *": expected 5 fields, found 4`)
+	assert.ErrorContains(t, err, "freezes[4].duration: must be greater than zero")
+	assert.ErrorContains(t, err, "freezes[4].time-zone: unknown time zone Mars/Olympus")
+	assert.ErrorContains(t, err, "freezes[4].types: invalid glob [broken")
+	assert.ErrorContains(t, err, "freezes[4].contexts: invalid regular expression /(broken/")
+	assert.ErrorContains(t, err, "freezes[5]: either start and end or schedule and duration must be set, not both")
+}
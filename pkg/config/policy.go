@@ -0,0 +1,19 @@
+package config
+
+// TypePolicy restricts how a single condition type may be set.
+// The zero TypePolicy allows everything.
+type TypePolicy struct {
+	// Statuses is the list of statuses the condition type may be set to, compared case-insensitively.
+	// An empty list allows any status.
+	Statuses []string `json:"statuses,omitempty"`
+	// Reasons is the list of reasons the condition type may be set with. An empty list allows any reason.
+	Reasons []string `json:"reasons,omitempty"`
+	// ReasonPattern is a regular expression every reason of the condition type must match.
+	ReasonPattern string `json:"reason-pattern,omitempty"`
+	// RequireMessage indicates whether the condition type must be set with a message.
+	RequireMessage bool `json:"require-message,omitempty"`
+	// DisallowRemove indicates whether removing the condition type is forbidden.
+	DisallowRemove bool `json:"disallow-remove,omitempty"`
+	// NodeSelector is a label selector restricting the nodes the condition type may be set on.
+	NodeSelector string `json:"node-selector,omitempty"`
+}
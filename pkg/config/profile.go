@@ -0,0 +1,121 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// Profile overrides the configuration for a single kubeconfig context or cluster.
+// Fields left unset in the profile keep the value of the top-level configuration.
+type Profile struct {
+	// WhoAmI overrides Config.WhoAmI.
+	WhoAmI *bool `json:"prepend-whoami,omitempty"`
+	// WhoAmISource overrides Config.WhoAmISource.
+	WhoAmISource string `json:"whoami-source,omitempty"`
+	// WhoAmIFormat overrides Config.WhoAmIFormat.
+	WhoAmIFormat string `json:"whoami-format,omitempty"`
+	// AllowList replaces Config.AllowList. An empty list allows every condition type.
+	AllowList []string `json:"allow-list,omitempty"`
+	// DenyList replaces Config.DenyList. A cluster policy adds its entries to Config.DenyList instead.
+	DenyList []string `json:"deny-list,omitempty"`
+	// ProtectedTypes replaces Config.ProtectedTypes. An empty list disables the protection.
+	// A cluster policy adds its entries to Config.ProtectedTypes instead.
+	ProtectedTypes []string `json:"protected-types,omitempty"`
+	// MaxMessageLength overrides Config.MaxMessageLength.
+	MaxMessageLength int `json:"max-message-length,omitempty"`
+	// MaxNodes overrides Config.MaxNodes.
+	MaxNodes int `json:"max-nodes,omitempty"`
+	// MaxNodesPercent overrides Config.MaxNodesPercent.
+	MaxNodesPercent int `json:"max-nodes-percent,omitempty"`
+	// Policies are merged into Config.Policies, replacing the policy of the same condition type.
+	Policies map[string]TypePolicy `json:"policies,omitempty"`
+	// Teams are merged into Config.Teams, replacing the team of the same name.
+	Teams map[string]Team `json:"teams,omitempty"`
+	// DefaultTeam overrides Config.DefaultTeam.
+	DefaultTeam string `json:"default-team,omitempty"`
+	// Freezes are added to Config.Freezes, a profile or cluster policy cannot lift a freeze.
+	Freezes []Freeze `json:"freezes,omitempty"`
+}
+
+// ForContext returns the configuration with the profile of the kubeconfig context applied.
+// The profile named after the context takes precedence over the profile named after its cluster.
+// The configuration is returned unchanged if neither has a profile.
+func (c *Config) ForContext(context, cluster string) *Config {
+	if profile, ok := c.Profiles[context]; ok && context != "" {
+		return c.apply(profile)
+	}
+
+	if profile, ok := c.Profiles[cluster]; ok && cluster != "" {
+		return c.apply(profile)
+	}
+
+	return c
+}
+
+// apply returns a copy of the configuration with the profile applied.
+func (c *Config) apply(profile Profile) *Config {
+	applied := *c
+
+	if profile.WhoAmI != nil {
+		applied.WhoAmI = *profile.WhoAmI
+	}
+
+	if profile.WhoAmISource != "" {
+		applied.WhoAmISource = profile.WhoAmISource
+	}
+
+	if profile.WhoAmIFormat != "" {
+		applied.WhoAmIFormat = profile.WhoAmIFormat
+	}
+
+	if profile.AllowList != nil {
+		applied.AllowList = profile.AllowList
+	}
+
+	if profile.DenyList != nil {
+		applied.DenyList = profile.DenyList
+	}
+
+	if profile.ProtectedTypes != nil {
+		applied.ProtectedTypes = profile.ProtectedTypes
+	}
+
+	if profile.MaxMessageLength != 0 {
+		applied.MaxMessageLength = profile.MaxMessageLength
+	}
+
+	if profile.MaxNodes != 0 {
+		applied.MaxNodes = profile.MaxNodes
+	}
+
+	if profile.MaxNodesPercent != 0 {
+		applied.MaxNodesPercent = profile.MaxNodesPercent
+	}
+
+	if profile.Policies != nil {
+		applied.Policies = merge(c.Policies, profile.Policies)
+	}
+
+	if profile.Teams != nil {
+		applied.Teams = merge(c.Teams, profile.Teams)
+	}
+
+	if profile.DefaultTeam != "" {
+		applied.DefaultTeam = profile.DefaultTeam
+	}
+
+	if profile.Freezes != nil {
+		applied.Freezes = slices.Concat(c.Freezes, profile.Freezes)
+	}
+
+	return &applied
+}
+
+// merge returns a new map holding the entries of base overridden by the entries of override.
+func merge[V any](base, override map[string]V) map[string]V {
+	merged := make(map[string]V, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+
+	return merged
+}
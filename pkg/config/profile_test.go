@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForContext(t *testing.T) {
+	enabled := true
+	cfg := &Config{
+		AllowList: []string{"NodeMaintenance"},
+		Policies:  map[string]TypePolicy{"NodeMaintenance": {RequireMessage: true}},
+		Teams:     map[string]Team{"storage": {Prefix: "storage.example.com"}},
+		MaxNodes:  50,
+		Freezes:   []Freeze{{Name: "global", Schedule: "0 16 * * 5", Duration: "64h"}},
+		Profiles: map[string]Profile{
+			"prod": {
+				WhoAmI:          &enabled,
+				DenyList:        []string{"UnderInvestigation"},
+				Policies:        map[string]TypePolicy{"NodeMaintenance": {DisallowRemove: true}},
+				Teams:           map[string]Team{"network": {Prefix: "network.example.com"}},
+				MaxNodes:        5,
+				MaxNodesPercent: 10,
+				Freezes:         []Freeze{{Name: "prod", Start: "2026-12-20T00:00:00Z", End: "2027-01-04T00:00:00Z"}},
+			},
+			"dev-cluster": {AllowList: []string{}},
+		},
+	}
+
+	t.Run("Context profile", func(t *testing.T) {
+		got := cfg.ForContext("prod", "prod-cluster")
+
+		assert.True(t, got.WhoAmI)
+		assert.Equal(t, []string{"NodeMaintenance"}, got.AllowList)
+		assert.Equal(t, []string{"UnderInvestigation"}, got.DenyList)
+		assert.Equal(t, TypePolicy{DisallowRemove: true}, got.Policies["NodeMaintenance"])
+		assert.Len(t, got.Teams, 2)
+		assert.Equal(t, 5, got.MaxNodes)
+		assert.Equal(t, 10, got.MaxNodesPercent)
+		assert.Equal(t, []string{"global", "prod"}, []string{got.Freezes[0].Name, got.Freezes[1].Name})
+
+		// The top-level configuration is left untouched.
+		assert.False(t, cfg.WhoAmI)
+		assert.Len(t, cfg.Teams, 1)
+	})
+
+	t.Run("Cluster profile", func(t *testing.T) {
+		got := cfg.ForContext("dev-admin", "dev-cluster")
+
+		assert.Equal(t, []string{}, got.AllowList)
+		assert.False(t, got.WhoAmI)
+		assert.Equal(t, 50, got.MaxNodes)
+	})
+
+	t.Run("Context takes precedence over cluster", func(t *testing.T) {
+		assert.True(t, cfg.ForContext("prod", "dev-cluster").WhoAmI)
+	})
+
+	t.Run("No profile", func(t *testing.T) {
+		assert.Same(t, cfg, cfg.ForContext("staging", "staging-cluster"))
+		assert.Same(t, cfg, cfg.ForContext("", ""))
+	})
+}
+
+func TestValidateProfiles(t *testing.T) {
+	cfg := &Config{
+		AllowList: []string{"[broken"},
+		Profiles: map[string]Profile{
+			"prod": {DenyList: []string{"/(broken/"}, DefaultTeam: "network"},
+		},
+	}
+
+	err := cfg.Validate()
+	assert.EqualError(t, err, "allow-list: invalid glob [broken: syntax error in pattern\n"+
+		"profiles.prod.deny-list: invalid regular expression /(broken/: error parsing regexp: missing closing ): `(broken`\n"+
+		"profiles.prod.default-team: unknown team network")
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// validStatuses are the statuses a TypePolicy may allow, compared case-insensitively.
+var validStatuses = []string{"true", "false", "unknown"}
+
+// Validate checks the values of the configuration that cannot be checked while decoding it,
+// such as the syntax of patterns, regular expressions and label selectors.
+// It returns every problem found, joined into a single error.
+func (c *Config) Validate() error {
+	errs := c.validate()
+
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		for _, err := range c.validateProfile(c.Profiles[name]) {
+			errs = append(errs, fmt.Errorf("profiles.%s.%w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateWebhooks checks the values of the webhooks, so a command can refuse to change anything before a webhook
+// it would notify afterwards turns out to be invalid. It returns every problem found, joined into a single error.
+func (c *Config) ValidateWebhooks() error {
+	var errs []error
+	for i, webhook := range c.Webhooks {
+		errs = append(errs, webhook.validate(fmt.Sprintf("webhooks[%d]", i))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateProfile returns the problems the profile introduces when applied to the configuration.
+// A profile inherits the values it does not override, so problems of those values are not reported for it.
+func (c *Config) validateProfile(profile Profile) []error {
+	inherited := map[string]bool{}
+	for _, err := range c.validate() {
+		inherited[err.Error()] = true
+	}
+
+	var errs []error
+	for _, err := range c.apply(profile).validate() {
+		if !inherited[err.Error()] {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// validate returns every problem with the values of the configuration, ignoring its profiles.
+func (c *Config) validate() []error {
+	var errs []error
+
+	if c.Version < 0 || c.Version > SchemaVersion {
+		errs = append(errs, fmt.Errorf("version: unsupported version %d, must be at most %d", c.Version, SchemaVersion))
+	}
+
+	if source := c.IdentitySource(); source != WhoAmISourceOS && source != WhoAmISourceKubernetes {
+		errs = append(errs, fmt.Errorf("whoami-source: invalid source %q, must be one of [%s %s]", source, WhoAmISourceOS, WhoAmISourceKubernetes))
+	}
+
+	if _, err := template.New("whoami-format").Parse(c.IdentityFormat()); err != nil {
+		errs = append(errs, fmt.Errorf("whoami-format: %w", err))
+	}
+
+	for _, entry := range c.AllowList {
+		if err := pattern.Validate(entry); err != nil {
+			errs = append(errs, fmt.Errorf("allow-list: %w", err))
+		}
+	}
+
+	for _, entry := range c.DenyList {
+		if err := pattern.Validate(entry); err != nil {
+			errs = append(errs, fmt.Errorf("deny-list: %w", err))
+		}
+	}
+
+	if c.MaxMessageLength < 0 {
+		errs = append(errs, fmt.Errorf("max-message-length: must not be negative"))
+	}
+
+	if c.ConfirmThreshold < 0 {
+		errs = append(errs, fmt.Errorf("confirm-threshold: must not be negative"))
+	}
+
+	if c.MaxNodes < 0 {
+		errs = append(errs, fmt.Errorf("max-nodes: must not be negative"))
+	}
+
+	if c.MaxNodesPercent < 0 || c.MaxNodesPercent > 100 {
+		errs = append(errs, fmt.Errorf("max-nodes-percent: must be between 0 and 100"))
+	}
+
+	if c.AuditLogMaxSize < 0 {
+		errs = append(errs, fmt.Errorf("audit-log-max-size: must not be negative"))
+	}
+
+	if c.AuditLogMaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("audit-log-max-backups: must not be negative"))
+	}
+
+	for _, conditionType := range slices.Sorted(maps.Keys(c.Policies)) {
+		errs = append(errs, c.Policies[conditionType].validate(fmt.Sprintf("policies.%s", conditionType))...)
+	}
+
+	for i, webhook := range c.Webhooks {
+		errs = append(errs, webhook.validate(fmt.Sprintf("webhooks[%d]", i))...)
+	}
+
+	for i, freeze := range c.Freezes {
+		errs = append(errs, freeze.validate(fmt.Sprintf("freezes[%d]", i))...)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Teams)) {
+		if c.Teams[name].Prefix == "" {
+			errs = append(errs, fmt.Errorf("teams.%s.prefix: must not be empty", name))
+		}
+	}
+
+	if _, ok := c.Teams[c.DefaultTeam]; c.DefaultTeam != "" && !ok {
+		errs = append(errs, fmt.Errorf("default-team: unknown team %s", c.DefaultTeam))
+	}
+
+	if _, _, err := c.ClusterPolicyRef(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// validate checks the values of the policy, prefixing every problem with the key of the policy.
+func (p TypePolicy) validate(key string) []error {
+	var errs []error
+
+	for _, status := range p.Statuses {
+		if !slices.Contains(validStatuses, strings.ToLower(status)) {
+			errs = append(errs, fmt.Errorf("%s.statuses: invalid status %q, must be one of %v", key, status, validStatuses))
+		}
+	}
+
+	if p.ReasonPattern != "" {
+		if _, err := regexp.Compile(p.ReasonPattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s.reason-pattern: %w", key, err))
+		}
+	}
+
+	if p.NodeSelector != "" {
+		if _, err := labels.Parse(p.NodeSelector); err != nil {
+			errs = append(errs, fmt.Errorf("%s.node-selector: %w", key, err))
+		}
+	}
+
+	return errs
+}
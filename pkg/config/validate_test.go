@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		cfg := &Config{
+			AllowList: []string{"team-a.example.com/*", "/^team-b/"},
+			DenyList:  []string{"Ready"},
+			Policies: map[string]TypePolicy{
+				"NodeMaintenance": {Statuses: []string{"True", "false"}, ReasonPattern: "^Maintenance", NodeSelector: "role=worker"},
+			},
+			Teams:       map[string]Team{"storage": {Prefix: "storage.example.com"}},
+			DefaultTeam: "storage",
+		}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cfg := &Config{
+			Version:          SchemaVersion + 1,
+			WhoAmISource:     "ldap",
+			WhoAmIFormat:     "{{.Username",
+			AllowList:        []string{"[broken"},
+			DenyList:         []string{"/(broken/"},
+			MaxMessageLength: -1,
+			AuditLogMaxSize:  -1,
+			ConfirmThreshold: -1,
+			MaxNodes:         -1,
+			MaxNodesPercent:  101,
+			Policies: map[string]TypePolicy{
+				"NodeMaintenance": {Statuses: []string{"maybe"}, ReasonPattern: "(", NodeSelector: "a in (b"},
+			},
+			Teams:       map[string]Team{"storage": {}},
+			DefaultTeam: "network",
+		}
+
+		err := cfg.Validate()
+		assert.ErrorContains(t, err, "version: unsupported version 2, must be at most 1")
+		assert.ErrorContains(t, err, `whoami-source: invalid source "ldap", must be one of [os kubernetes]`)
+		assert.ErrorContains(t, err, "whoami-format: template: whoami-format:1: unclosed action")
+		assert.ErrorContains(t, err, "allow-list: invalid glob [broken")
+		assert.ErrorContains(t, err, "deny-list: invalid regular expression /(broken/")
+		assert.ErrorContains(t, err, "max-message-length: must not be negative")
+		assert.ErrorContains(t, err, "audit-log-max-size: must not be negative")
+		assert.ErrorContains(t, err, "confirm-threshold: must not be negative")
+		assert.ErrorContains(t, err, "max-nodes: must not be negative")
+		assert.ErrorContains(t, err, "max-nodes-percent: must be between 0 and 100")
+		assert.ErrorContains(t, err, `policies.NodeMaintenance.statuses: invalid status "maybe"`)
+		assert.ErrorContains(t, err, "policies.NodeMaintenance.reason-pattern:")
+		assert.ErrorContains(t, err, "policies.NodeMaintenance.node-selector:")
+		assert.ErrorContains(t, err, "teams.storage.prefix: must not be empty")
+		assert.ErrorContains(t, err, "default-team: unknown team network")
+	})
+}
+
+func TestEffective(t *testing.T) {
+	cfg := (&Config{WhoAmI: true}).Effective()
+
+	assert.True(t, cfg.WhoAmI)
+	assert.Equal(t, []string{}, cfg.AllowList)
+	assert.Equal(t, DefaultProtectedTypes, cfg.ProtectedTypes)
+	assert.Equal(t, DefaultMaxMessageLength, cfg.MaxMessageLength)
+	assert.Equal(t, WhoAmISourceOS, cfg.WhoAmISource)
+	assert.Equal(t, DefaultWhoAmIFormat, cfg.WhoAmIFormat)
+	assert.Equal(t, DefaultConfirmThreshold, cfg.ConfirmThreshold)
+	assert.Equal(t, DefaultAuditLogMaxSize, cfg.AuditLogMaxSize)
+	assert.Equal(t, DefaultAuditLogMaxBackups, cfg.AuditLogMaxBackups)
+
+	cfg = (&Config{ConfirmThreshold: 3, AuditLogMaxSize: 50, AuditLogMaxBackups: 2}).Effective()
+	assert.Equal(t, 3, cfg.ConfirmThreshold)
+	assert.Equal(t, 50, cfg.AuditLogMaxSize)
+	assert.Equal(t, 2, cfg.AuditLogMaxBackups)
+}
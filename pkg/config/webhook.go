@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/pattern"
+)
+
+// Payload formats of a Webhook.
+const (
+	// WebhookFormatJSON posts the changes as a generic JSON object.
+	WebhookFormatJSON string = "json"
+	// WebhookFormatSlack posts a Slack-compatible message with a text field.
+	WebhookFormatSlack string = "slack"
+)
+
+// DefaultWebhookTimeout is the timeout of a single webhook request when the webhook does not set one.
+const DefaultWebhookTimeout time.Duration = 5 * time.Second
+
+// WebhookTemplateFuncs are the functions available to webhook templates in addition to the text/template builtins.
+// json encodes a value as JSON, so strings can be embedded in JSON payloads safely: {"text": {{json .Summary}}}.
+var WebhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// webhookFormats are the valid payload formats of a Webhook.
+var webhookFormats = []string{WebhookFormatJSON, WebhookFormatSlack}
+
+// Webhook is an HTTP endpoint notified when conditions change.
+type Webhook struct {
+	// Name identifies the webhook in warnings.
+	Name string `json:"name"`
+	// URL is the endpoint the changes are posted to.
+	URL string `json:"url"`
+	// Types routes the changes of the condition types, globs or regular expressions wrapped in slashes to the webhook.
+	// An empty list routes every change to the webhook.
+	Types []string `json:"types,omitempty"`
+	// Format is the payload format, WebhookFormatJSON (the default) or WebhookFormatSlack.
+	Format string `json:"format,omitempty"`
+	// Template is a text/template rendering the payload, it takes precedence over Format.
+	Template string `json:"template,omitempty"`
+	// Headers are additional HTTP headers sent with every request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Timeout is the timeout of a single request, e.g. 10s. When unset it defaults to DefaultWebhookTimeout.
+	Timeout string `json:"timeout,omitempty"`
+	// Retries is the number of times a failed request is retried.
+	Retries int `json:"retries,omitempty"`
+}
+
+// PayloadFormat returns the payload format of the webhook.
+func (w Webhook) PayloadFormat() string {
+	if w.Format == "" {
+		return WebhookFormatJSON
+	}
+
+	return w.Format
+}
+
+// RequestTimeout returns the timeout of a single request to the webhook.
+func (w Webhook) RequestTimeout() (time.Duration, error) {
+	if w.Timeout == "" {
+		return DefaultWebhookTimeout, nil
+	}
+
+	return time.ParseDuration(w.Timeout)
+}
+
+// Routes reports whether changes of the condition type are routed to the webhook.
+func (w Webhook) Routes(conditionType string) (bool, error) {
+	if len(w.Types) == 0 {
+		return true, nil
+	}
+
+	return matchAny(w.Types, conditionType)
+}
+
+// validate checks the values of the webhook, prefixing every problem with the key of the webhook.
+func (w Webhook) validate(key string) []error {
+	var errs []error
+
+	if u, err := url.Parse(w.URL); err != nil {
+		errs = append(errs, fmt.Errorf("%s.url: %w", key, err))
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		errs = append(errs, fmt.Errorf("%s.url: must be an http or https URL, not %q", key, w.URL))
+	}
+
+	for _, entry := range w.Types {
+		if err := pattern.Validate(entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s.types: %w", key, err))
+		}
+	}
+
+	if !slices.Contains(webhookFormats, w.PayloadFormat()) {
+		errs = append(errs, fmt.Errorf("%s.format: invalid format %q, must be one of %v", key, w.Format, webhookFormats))
+	}
+
+	if w.Template != "" {
+		if _, err := template.New(key).Funcs(WebhookTemplateFuncs).Parse(w.Template); err != nil {
+			errs = append(errs, fmt.Errorf("%s.template: %w", key, err))
+		}
+	}
+
+	if timeout, err := w.RequestTimeout(); err != nil {
+		errs = append(errs, fmt.Errorf("%s.timeout: %w", key, err))
+	} else if timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s.timeout: must be greater than zero", key))
+	}
+
+	if w.Retries < 0 {
+		errs = append(errs, fmt.Errorf("%s.retries: must not be negative", key))
+	}
+
+	return errs
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRoutes(t *testing.T) {
+	ok, err := Webhook{}.Routes("NodeMaintenance")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	webhook := Webhook{Types: []string{"storage.example.com/*"}}
+
+	ok, err = webhook.Routes("storage.example.com/Healthy")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = webhook.Routes("NodeMaintenance")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestWebhookRequestTimeout(t *testing.T) {
+	timeout, err := Webhook{}.RequestTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWebhookTimeout, timeout)
+
+	timeout, err = Webhook{Timeout: "10s"}.RequestTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, timeout)
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	cfg := &Config{
+		Webhooks: []Webhook{
+			{Name: "slack", URL: "https://hooks.slack.com/services/T000/B000/XXX", Format: WebhookFormatSlack, Types: []string{"NodeMaintenance"}},
+			{Name: "generic", URL: "https://example.com/hook", Template: `{"text": {{json .Summary}}}`, Timeout: "2s", Retries: 3},
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+	assert.NoError(t, cfg.ValidateWebhooks())
+
+	cfg = &Config{
+		Webhooks: []Webhook{
+			{Name: "broken", URL: "ftp://example.com", Format: "xml", Types: []string{"[broken"}, Template: "{{.Node", Timeout: "soon", Retries: -1},
+		},
+	}
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, `webhooks[0].url: must be an http or https URL, not "ftp://example.com"`)
+	assert.ErrorContains(t, err, "webhooks[0].types: invalid glob [broken")
+	assert.ErrorContains(t, err, `webhooks[0].format: invalid format "xml", must be one of [json slack]`)
+	assert.ErrorContains(t, err, "webhooks[0].template:")
+	assert.ErrorContains(t, err, `webhooks[0].timeout: time: invalid duration "soon"`)
+	assert.ErrorContains(t, err, "webhooks[0].retries: must not be negative")
+
+	// ValidateWebhooks reports the same problems without the rest of the configuration.
+	assert.Equal(t, err.Error(), cfg.ValidateWebhooks().Error())
+}
@@ -0,0 +1,140 @@
+// Package cron implements the five field cron expressions used to schedule recurring freeze windows.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is the range of values a cron field accepts.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	// values holds, for every field, whether each value of the field matches.
+	values [5][]bool
+
+	// anyDay and anyWeekday record whether the day of month and day of week fields start with '*', e.g. '*' or '*/2'.
+	anyDay, anyWeekday bool
+}
+
+// Parse parses a standard five field cron expression: minute, hour, day of month, month and day of week.
+// Every field may be '*', a value, a range (1-5) or a comma separated list of them, optionally with a step (*/15, 1-5/2).
+// Sunday is day 0 or 7.
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, found %d", expr, len(fields), len(parts))
+	}
+
+	// Like cron, a day field starting with '*' is unrestricted, even with a step, so it does not combine with the other
+	// day field as a union.
+	s := &Schedule{anyDay: strings.HasPrefix(parts[2], "*"), anyWeekday: strings.HasPrefix(parts[4], "*")}
+	for i, f := range fields {
+		values, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s: %w", expr, f.name, err)
+		}
+
+		s.values[i] = values
+	}
+
+	// Sunday may be written as 7.
+	s.values[4][0] = s.values[4][0] || s.values[4][7]
+
+	return s, nil
+}
+
+// Matches reports whether the minute of t matches the schedule.
+// Like cron, when both the day of month and the day of week are restricted a day matching either matches.
+func (s *Schedule) Matches(t time.Time) bool {
+	if !s.values[0][t.Minute()] || !s.values[1][t.Hour()] || !s.values[3][int(t.Month())] {
+		return false
+	}
+
+	day, weekday := s.values[2][t.Day()], s.values[4][int(t.Weekday())]
+	if !s.anyDay && !s.anyWeekday {
+		return day || weekday
+	}
+
+	return day && weekday
+}
+
+// parseField returns which values of the field the expression selects.
+func parseField(expr string, f field) ([]bool, error) {
+	values := make([]bool, f.max+1)
+
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rangeExpr = item[:i]
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+
+			if high, err = parseValue(bounds[1], f); err != nil {
+				return nil, err
+			}
+
+			if low > high {
+				return nil, fmt.Errorf("invalid range %q", rangeExpr)
+			}
+		default:
+			value, err := parseValue(rangeExpr, f)
+			if err != nil {
+				return nil, err
+			}
+
+			low, high = value, value
+			if step > 1 {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// parseValue parses a single value of the field.
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, f.min, f.max)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,71 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr        string
+		expectedErr string
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 1,15 */2 1-5"},
+		{expr: "0 0 * * 7"},
+		{expr: "* * * *", expectedErr: `invalid cron expression "* * * *": expected 5 fields, found 4`},
+		{expr: "60 * * * *", expectedErr: `invalid cron expression "60 * * * *": minute: value 60 out of range [0, 59]`},
+		{expr: "* 5-1 * * *", expectedErr: `invalid cron expression "* 5-1 * * *": hour: invalid range "5-1"`},
+		{expr: "* * */0 * *", expectedErr: `invalid cron expression "* * */0 * *": day of month: invalid step in "*/0"`},
+		{expr: "* * * JAN *", expectedErr: `invalid cron expression "* * * JAN *": month: invalid value "JAN"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2026-12-25 is a Friday.
+	friday := time.Date(2026, time.December, 25, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{name: "Every minute", expr: "* * * * *", at: friday, want: true},
+		{name: "Friday evening", expr: "0-59 17-23 * * 5", at: friday, want: true},
+		{name: "Step", expr: "*/15 * * * *", at: friday, want: true},
+		{name: "Step from a value", expr: "10/20 * * * *", at: friday, want: true},
+		{name: "Step misses", expr: "*/20 * * * *", at: friday, want: false},
+		{name: "Wrong month", expr: "* * * 11 *", at: friday, want: false},
+		{name: "Sunday as 7", expr: "* * * * 7", at: friday.AddDate(0, 0, 2), want: true},
+		{name: "Day of month or day of week", expr: "* * 1 * 5", at: friday, want: true},
+		{name: "Neither day of month nor day of week", expr: "* * 1 * 1", at: friday, want: false},
+		{name: "Day of month with any weekday", expr: "* * 24 * *", at: friday, want: false},
+		{name: "Stepped day of month is unrestricted", expr: "* * */2 * 1", at: friday, want: false},
+		{name: "Stepped day of month and day of week both match", expr: "* * */2 * 1", at: friday.AddDate(0, 0, -4), want: true},
+		{name: "Stepped day of month misses on a matching weekday", expr: "* * */2 * 1", at: friday.AddDate(0, 0, 3), want: false},
+		{name: "Stepped day of week is unrestricted", expr: "* * 25 * */2", at: friday, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Matches(tt.at))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package jsonpatch
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -10,8 +12,10 @@ import (
 
 // basePath is a constant string that represents the base path in the JSON document where the operations are performed.
 // It is used in the formation of the path for the JSON Patch operation.
+// annotationsPath is the path in the JSON document of the node annotations.
 const (
-	basePath string = "/status/conditions"
+	basePath        string = "/status/conditions"
+	annotationsPath string = "/metadata/annotations"
 )
 
 // JsonPatch represents a JSON Patch operation.
@@ -28,12 +32,35 @@ type JsonPatch struct {
 	Value *corev1.NodeCondition `json:"value"`
 }
 
+// AnnotationPatch represents a JSON Patch operation on the node annotations.
+// It is sent in the same request as the condition operations, so a condition and its annotations change together.
+type AnnotationPatch struct {
+	// OP is the operation to be performed. It's a string and can be one of "add" or "remove".
+	OP string `json:"op"`
+	// Path is the string that contains the location in the JSON document where the operation is performed.
+	Path string `json:"path"`
+	// Value is either the annotation value, or the whole annotations object when the node has none yet.
+	Value interface{} `json:"value,omitempty"`
+}
+
+// TestPatch represents a JSON Patch "test" operation. The API server rejects the whole patch if the value at
+// Path differs, it guards the operations addressing a condition by index against the conditions changing in between.
+type TestPatch struct {
+	// OP is always "test".
+	OP string `json:"op"`
+	// Path is the string that contains the location in the JSON document of the tested value.
+	Path string `json:"path"`
+	// Value is the value expected at Path.
+	Value string `json:"value"`
+}
+
 // GenerateJsonPath is a function that generates a JSON Patch operation based on the provided parameters.
 // It takes four parameters:
 // - index: an integer that represents the index of the condition in the conditions array.
 // - remove: a boolean that indicates whether the operation is a remove operation.
 // - oldConditions: a pointer to a NodeCondition object that represents the old conditions.
 // - newConditions: a pointer to a NodeCondition object that represents the new conditions.
+// The heartbeat time is always set to now, the transition time only when the status changes.
 func GenerateJsonPath(index int, remove bool, oldConditions, newConditions *corev1.NodeCondition) JsonPatch {
 	jsonPatch := JsonPatch{
 		OP:   opType(index, remove),
@@ -68,12 +95,78 @@ func GenerateJsonPath(index int, remove bool, oldConditions, newConditions *core
 			jsonPatch.Value.Status = oldConditions.Status
 		}
 
+		// The transition time records when the status last changed, so it is kept when the status is unchanged.
+		if jsonPatch.Value.Status == oldConditions.Status {
+			jsonPatch.Value.LastTransitionTime = oldConditions.LastTransitionTime
+		}
 	}
 
 	// Return the JsonPatch object.
 	return jsonPatch
 }
 
+// GenerateTestPatch is a function that generates a JSON Patch test operation checking that the condition at index
+// still has the condition type. It is placed before every operation addressing the condition by index.
+func GenerateTestPatch(index int, conditionType corev1.NodeConditionType) TestPatch {
+	return TestPatch{
+		OP:    "test",
+		Path:  pathType(index) + "/type",
+		Value: string(conditionType),
+	}
+}
+
+// GenerateRemovePatches is a function that generates the JSON Patch remove operations for the conditions at the given indices.
+// The operations are ordered by descending index, so removing one condition never shifts the position of a condition
+// that is still to be removed. Duplicate indices are removed. Every remove operation is preceded by a test operation
+// checking the type of the condition, so a condition that moved since the conditions were read is never removed.
+func GenerateRemovePatches(conditions []corev1.NodeCondition, indices []int) []interface{} {
+	sorted := slices.Clone(indices)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	slices.Reverse(sorted)
+
+	patches := make([]interface{}, 0, 2*len(sorted))
+	for _, index := range sorted {
+		patches = append(patches, GenerateTestPatch(index, conditions[index].Type), GenerateJsonPath(index, true, nil, nil))
+	}
+
+	return patches
+}
+
+// GenerateAnnotationPatch is a function that generates a JSON Patch operation setting the annotation key to value.
+// If the node has no annotations yet the annotations object is added as a whole, as JSON Patch cannot add a member
+// to an object that does not exist. An "add" operation on an existing member replaces its value.
+func GenerateAnnotationPatch(annotations map[string]string, key, value string) AnnotationPatch {
+	if annotations == nil {
+		return GenerateAnnotationsPatch(map[string]string{key: value})
+	}
+
+	return AnnotationPatch{
+		OP:    "add",
+		Path:  annotationPath(key),
+		Value: value,
+	}
+}
+
+// GenerateAnnotationsPatch is a function that generates a JSON Patch operation adding the annotations object as a whole.
+// It is used for nodes without annotations, replacing any annotations the node may have.
+func GenerateAnnotationsPatch(annotations map[string]string) AnnotationPatch {
+	return AnnotationPatch{
+		OP:    "add",
+		Path:  annotationsPath,
+		Value: annotations,
+	}
+}
+
+// GenerateAnnotationRemovePatch is a function that generates a JSON Patch operation removing the annotation key.
+// The annotation must exist, otherwise the API server rejects the whole patch.
+func GenerateAnnotationRemovePatch(key string) AnnotationPatch {
+	return AnnotationPatch{
+		OP:   "remove",
+		Path: annotationPath(key),
+	}
+}
+
 // opType is a function that determines the operation type for a JSON Patch operation.
 // The function returns a string that represents the operation type.
 // If the remove parameter is true, the function returns "remove".
@@ -100,3 +193,12 @@ func pathType(index int) string {
 
 	return fmt.Sprintf("%s/%d", basePath, index)
 }
+
+// annotationPath is a function that generates the path of a single annotation.
+// The key is escaped as a JSON Pointer reference token, as annotation keys usually contain a "/".
+func annotationPath(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+
+	return fmt.Sprintf("%s/%s", annotationsPath, key)
+}
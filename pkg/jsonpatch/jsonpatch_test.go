@@ -131,3 +131,113 @@ func TestGenerateJsonPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateRemovePatches(t *testing.T) {
+	assert := assert.New(t)
+
+	conditions := []corev1.NodeCondition{{Type: "A"}, {Type: "B"}, {Type: "C"}, {Type: "D"}, {Type: "E"}}
+
+	tests := []struct {
+		name    string
+		indices []int
+		want    []string
+	}{
+		{"no indices", nil, []string{}},
+		{"single index", []int{2}, []string{"C", basePath + "/2"}},
+		{"unordered indices", []int{1, 4, 0}, []string{"E", basePath + "/4", "B", basePath + "/1", "A", basePath + "/0"}},
+		{"duplicate indices", []int{3, 3, 1}, []string{"D", basePath + "/3", "B", basePath + "/1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateRemovePatches(conditions, tt.indices)
+			ops := make([]string, 0, len(got))
+			for i, patch := range got {
+				if i%2 == 0 {
+					test := patch.(TestPatch)
+					assert.Equal("test", test.OP)
+					assert.Equal(got[i+1].(JsonPatch).Path+"/type", test.Path)
+					ops = append(ops, test.Value)
+					continue
+				}
+
+				remove := patch.(JsonPatch)
+				assert.Equal("remove", remove.OP)
+				assert.Nil(remove.Value)
+				ops = append(ops, remove.Path)
+			}
+			assert.Equal(tt.want, ops)
+		})
+	}
+}
+
+func TestGenerateAnnotationPatch(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        AnnotationPatch
+	}{
+		{
+			name:        "No annotations",
+			annotations: nil,
+			want: AnnotationPatch{
+				OP:    "add",
+				Path:  annotationsPath,
+				Value: map[string]string{"example.com/key": "value"},
+			},
+		},
+		{
+			name:        "Existing annotations",
+			annotations: map[string]string{"other": "value"},
+			want: AnnotationPatch{
+				OP:    "add",
+				Path:  annotationsPath + "/example.com~1key",
+				Value: "value",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(tt.want, GenerateAnnotationPatch(tt.annotations, "example.com/key", "value"))
+		})
+	}
+}
+
+func TestGenerateAnnotationRemovePatch(t *testing.T) {
+	assert := assert.New(t)
+
+	got := GenerateAnnotationRemovePatch("example.com/a~b")
+	assert.Equal("remove", got.OP)
+	assert.Equal(annotationsPath+"/example.com~1a~0b", got.Path)
+	assert.Nil(got.Value)
+}
+
+func TestGenerateTestPatch(t *testing.T) {
+	assert.Equal(t, TestPatch{OP: "test", Path: basePath + "/3/type", Value: "NodeMaintenance"}, GenerateTestPatch(3, "NodeMaintenance"))
+}
+
+func TestGenerateJsonPathTransitionTime(t *testing.T) {
+	transitioned := metav1.NewTime(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	old := &corev1.NodeCondition{Type: "NodeMaintenance", Status: corev1.ConditionTrue, Reason: "MaintenanceScheduled", LastTransitionTime: transitioned}
+
+	tests := []struct {
+		name        string
+		status      corev1.ConditionStatus
+		wantChanged bool
+	}{
+		{name: "Status changed", status: corev1.ConditionFalse, wantChanged: true},
+		{name: "Status unchanged", status: corev1.ConditionTrue},
+		{name: "Status kept", status: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateJsonPath(0, false, old, &corev1.NodeCondition{Type: "NodeMaintenance", Status: tt.status, Message: "postponed"})
+			assert.Equal(t, tt.wantChanged, !got.Value.LastTransitionTime.Equal(&transitioned))
+			assert.NotEqual(t, transitioned, got.Value.LastHeartbeatTime)
+		})
+	}
+}
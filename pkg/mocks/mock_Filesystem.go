@@ -21,6 +21,53 @@ func (_m *MockFilesystem) EXPECT() *MockFilesystem_Expecter {
 	return &MockFilesystem_Expecter{mock: &_m.Mock}
 }
 
+// MkdirAll provides a mock function with given fields: path, perm
+func (_m *MockFilesystem) MkdirAll(path string, perm fs.FileMode) error {
+	ret := _m.Called(path, perm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MkdirAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, fs.FileMode) error); ok {
+		r0 = rf(path, perm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFilesystem_MkdirAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MkdirAll'
+type MockFilesystem_MkdirAll_Call struct {
+	*mock.Call
+}
+
+// MkdirAll is a helper method to define mock.On call
+//   - path string
+//   - perm fs.FileMode
+func (_e *MockFilesystem_Expecter) MkdirAll(path interface{}, perm interface{}) *MockFilesystem_MkdirAll_Call {
+	return &MockFilesystem_MkdirAll_Call{Call: _e.mock.On("MkdirAll", path, perm)}
+}
+
+func (_c *MockFilesystem_MkdirAll_Call) Run(run func(path string, perm fs.FileMode)) *MockFilesystem_MkdirAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(fs.FileMode))
+	})
+	return _c
+}
+
+func (_c *MockFilesystem_MkdirAll_Call) Return(_a0 error) *MockFilesystem_MkdirAll_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFilesystem_MkdirAll_Call) RunAndReturn(run func(string, fs.FileMode) error) *MockFilesystem_MkdirAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ReadFile provides a mock function with given fields: name
 func (_m *MockFilesystem) ReadFile(name string) ([]byte, error) {
 	ret := _m.Called(name)
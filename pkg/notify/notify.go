@@ -0,0 +1,238 @@
+// Package notify posts condition changes to the webhooks of the configuration.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+)
+
+// Change is a change of a condition.
+type Change struct {
+	// Time is when the change was made.
+	Time time.Time `json:"time"`
+	// Context is the name of the kubeconfig context the change was made with.
+	Context string `json:"context,omitempty"`
+	// Actor is the identity of the user making the change.
+	Actor string `json:"actor,omitempty"`
+	// Node is the name of the node.
+	Node string `json:"node"`
+	// ConditionType is the type of the changed condition.
+	ConditionType string `json:"type"`
+	// Operation is the JSON Patch operation applied to the condition: add, replace or remove.
+	Operation string `json:"op"`
+	// From is the status before the change, it is empty if the condition was added.
+	From string `json:"from,omitempty"`
+	// To is the status after the change, it is empty if the condition was removed.
+	To string `json:"to,omitempty"`
+	// Reason is the reason of the condition after the change.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the condition after the change.
+	Message string `json:"message,omitempty"`
+}
+
+// Summary describes the change in a single line, it is a line of the Summary of a Batch.
+func (c Change) Summary() string {
+	summary := fmt.Sprintf("condition %s on node %s changed from %s to %s", c.ConditionType, c.Node, statusOrNone(c.From), statusOrNone(c.To))
+	if c.Actor != "" {
+		summary += " by " + c.Actor
+	}
+
+	if c.Message != "" {
+		summary += ": " + c.Message
+	}
+
+	return summary
+}
+
+// maxSummaryLines is the number of changes described in the summary of a batch, further changes are counted.
+const maxSummaryLines int = 20
+
+// Batch holds the changes of a single run routed to a webhook. It is the data webhook templates are executed with.
+type Batch struct {
+	// Changes are the changes, in the order they were made.
+	Changes []Change `json:"changes"`
+}
+
+// Summary describes the changes, one per line. Only the first maxSummaryLines changes are described, so a run
+// changing many nodes does not produce an unreadable message.
+func (b Batch) Summary() string {
+	lines := make([]string, 0, min(len(b.Changes), maxSummaryLines)+1)
+	for i, change := range b.Changes {
+		if i == maxSummaryLines {
+			lines = append(lines, fmt.Sprintf("... and %d more changes", len(b.Changes)-maxSummaryLines))
+			break
+		}
+
+		lines = append(lines, change.Summary())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Notifier posts changes to webhooks.
+type Notifier struct {
+	// webhooks are the webhooks notified.
+	webhooks []config.Webhook
+
+	// client sends the requests, the timeout of each request is set per webhook.
+	client *http.Client
+
+	// backoff returns how long to wait before the given retry, it is replaced in tests.
+	backoff func(retry int) time.Duration
+}
+
+// New returns a Notifier posting to the webhooks.
+func New(webhooks []config.Webhook) *Notifier {
+	return &Notifier{
+		webhooks: webhooks,
+		client:   &http.Client{},
+		backoff: func(retry int) time.Duration {
+			return time.Duration(retry) * time.Second
+		},
+	}
+}
+
+// Notify posts the changes routed to each webhook in a single request, so a run changing many nodes sends one
+// request per webhook rather than one per change. The webhooks are notified concurrently. Failed requests are retried
+// as configured, the errors of the webhooks that still fail are returned joined.
+func (n *Notifier) Notify(ctx context.Context, changes []Change) error {
+	errs := make([]error, len(n.webhooks))
+
+	var wg sync.WaitGroup
+	for i, webhook := range n.webhooks {
+		var routed []Change
+		for _, change := range changes {
+			ok, err := webhook.Routes(change.ConditionType)
+			if err != nil {
+				errs[i] = fmt.Errorf("webhook %s: %w", webhook.Name, err)
+				break
+			}
+
+			if ok {
+				routed = append(routed, change)
+			}
+		}
+
+		if errs[i] != nil || len(routed) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := n.send(ctx, webhook, Batch{Changes: routed}); err != nil {
+				errs[i] = fmt.Errorf("webhook %s: %w", webhook.Name, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// send posts the batch to the webhook, retrying network errors, 429 and 5xx responses.
+func (n *Notifier) send(ctx context.Context, webhook config.Webhook, batch Batch) error {
+	body, err := payload(webhook, batch)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := webhook.RequestTimeout()
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(ctx, webhook, body, timeout)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= webhook.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(n.backoff(attempt + 1)):
+		}
+	}
+}
+
+// post sends a single request to the webhook. It reports whether a failed request may be retried.
+func (n *Notifier) post(ctx context.Context, webhook config.Webhook, body []byte, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected response %s", resp.Status)
+}
+
+// payload renders the request body of the batch for the webhook.
+func payload(webhook config.Webhook, batch Batch) ([]byte, error) {
+	if webhook.Template != "" {
+		tmpl, err := template.New(webhook.Name).Funcs(config.WebhookTemplateFuncs).Parse(webhook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, batch); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+
+		return b.Bytes(), nil
+	}
+
+	if webhook.PayloadFormat() == config.WebhookFormatSlack {
+		return json.Marshal(map[string]string{"text": batch.Summary()})
+	}
+
+	return json.Marshal(batch)
+}
+
+// statusOrNone returns the status, or <none> if the condition does not exist.
+func statusOrNone(status string) string {
+	if status == "" {
+		return "<none>"
+	}
+
+	return status
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devbytes-cloud/conditioner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a local webhook stand-in recording the requests it receives.
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	statuses []int
+}
+
+// handler returns the handler of the stand-in. It answers with the queued statuses in order, then 200 OK.
+func (r *recorder) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, string(body))
+
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+	}
+}
+
+// newTestNotifier returns a Notifier for the webhooks that does not wait between retries.
+func newTestNotifier(webhooks ...config.Webhook) *Notifier {
+	n := New(webhooks)
+	n.backoff = func(int) time.Duration { return 0 }
+	return n
+}
+
+var testChange = Change{
+	Time:          time.Date(2024, 9, 1, 7, 21, 47, 0, time.UTC),
+	Context:       "prod",
+	Actor:         "jane@example.com",
+	Node:          "worker-01",
+	ConditionType: "NodeMaintenance",
+	Operation:     "replace",
+	From:          "False",
+	To:            "True",
+	Reason:        "MaintenanceScheduled",
+	Message:       `draining "now"`,
+}
+
+func TestNotifyFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook config.Webhook
+		want    string
+	}{
+		{
+			name:    "Generic JSON",
+			webhook: config.Webhook{Name: "generic"},
+			want:    `{"changes":[{"time":"2024-09-01T07:21:47Z","context":"prod","actor":"jane@example.com","node":"worker-01","type":"NodeMaintenance","op":"replace","from":"False","to":"True","reason":"MaintenanceScheduled","message":"draining \"now\""}]}`,
+		},
+		{
+			name:    "Slack",
+			webhook: config.Webhook{Name: "slack", Format: config.WebhookFormatSlack},
+			want:    `{"text":"condition NodeMaintenance on node worker-01 changed from False to True by jane@example.com: draining \"now\""}`,
+		},
+		{
+			name:    "Template",
+			webhook: config.Webhook{Name: "template", Template: `{"summary": {{json .Summary}}, "node": "{{(index .Changes 0).Node}}"}`},
+			want:    `{"summary": "condition NodeMaintenance on node worker-01 changed from False to True by jane@example.com: draining \"now\"", "node": "worker-01"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			server := httptest.NewServer(r.handler())
+			defer server.Close()
+
+			tt.webhook.URL = server.URL
+			tt.webhook.Headers = map[string]string{"Authorization": "Bearer secret"}
+
+			require.NoError(t, newTestNotifier(tt.webhook).Notify(context.Background(), []Change{testChange}))
+			require.Len(t, r.bodies, 1)
+			assert.Equal(t, tt.want, r.bodies[0])
+			assert.Equal(t, http.MethodPost, r.requests[0].Method)
+			assert.Equal(t, "application/json", r.requests[0].Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer secret", r.requests[0].Header.Get("Authorization"))
+
+			if tt.webhook.Template == "" {
+				assert.True(t, json.Valid([]byte(r.bodies[0])))
+			}
+		})
+	}
+}
+
+func TestNotifyRouting(t *testing.T) {
+	r := &recorder{}
+	server := httptest.NewServer(r.handler())
+	defer server.Close()
+
+	webhook := config.Webhook{Name: "storage", URL: server.URL, Types: []string{"storage.example.com/*", "NodeMaintenance"}}
+	changes := []Change{
+		{Node: "worker-01", ConditionType: "storage.example.com/Healthy", To: "True"},
+		{Node: "worker-01", ConditionType: "network.example.com/Healthy", To: "True"},
+		{Node: "worker-01", ConditionType: "NodeMaintenance", To: "True"},
+	}
+
+	require.NoError(t, newTestNotifier(webhook).Notify(context.Background(), changes))
+	require.Len(t, r.bodies, 1)
+	assert.Contains(t, r.bodies[0], "storage.example.com/Healthy")
+	assert.Contains(t, r.bodies[0], "NodeMaintenance")
+	assert.NotContains(t, r.bodies[0], "network.example.com/Healthy")
+
+	r.bodies = nil
+	require.NoError(t, newTestNotifier(webhook).Notify(context.Background(), changes[1:2]))
+	assert.Empty(t, r.bodies)
+}
+
+func TestNotifyBatches(t *testing.T) {
+	slack := &recorder{}
+	slackServer := httptest.NewServer(slack.handler())
+	defer slackServer.Close()
+
+	generic := &recorder{}
+	genericServer := httptest.NewServer(generic.handler())
+	defer genericServer.Close()
+
+	changes := make([]Change, 25)
+	for i := range changes {
+		changes[i] = Change{Node: fmt.Sprintf("worker-%02d", i), ConditionType: "NodeMaintenance", To: "True"}
+	}
+
+	n := newTestNotifier(
+		config.Webhook{Name: "slack", URL: slackServer.URL, Format: config.WebhookFormatSlack},
+		config.Webhook{Name: "generic", URL: genericServer.URL},
+	)
+	require.NoError(t, n.Notify(context.Background(), changes))
+
+	require.Len(t, generic.bodies, 1)
+	var batch Batch
+	require.NoError(t, json.Unmarshal([]byte(generic.bodies[0]), &batch))
+	assert.Len(t, batch.Changes, 25)
+
+	require.Len(t, slack.bodies, 1)
+	var message map[string]string
+	require.NoError(t, json.Unmarshal([]byte(slack.bodies[0]), &message))
+	lines := strings.Split(message["text"], "\n")
+	require.Len(t, lines, 21)
+	assert.Equal(t, "condition NodeMaintenance on node worker-00 changed from <none> to True", lines[0])
+	assert.Equal(t, "... and 5 more changes", lines[20])
+}
+
+func TestNotifyRetries(t *testing.T) {
+	t.Run("Success: retried until accepted", func(t *testing.T) {
+		r := &recorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+		server := httptest.NewServer(r.handler())
+		defer server.Close()
+
+		webhook := config.Webhook{Name: "on-call", URL: server.URL, Retries: 2}
+		require.NoError(t, newTestNotifier(webhook).Notify(context.Background(), []Change{testChange}))
+		assert.Len(t, r.bodies, 3)
+	})
+
+	t.Run("Error: retries exhausted", func(t *testing.T) {
+		r := &recorder{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+		server := httptest.NewServer(r.handler())
+		defer server.Close()
+
+		webhook := config.Webhook{Name: "on-call", URL: server.URL, Retries: 1}
+		err := newTestNotifier(webhook).Notify(context.Background(), []Change{testChange})
+		assert.EqualError(t, err, "webhook on-call: unexpected response 502 Bad Gateway")
+		assert.Len(t, r.bodies, 2)
+	})
+
+	t.Run("Error: client errors are not retried", func(t *testing.T) {
+		r := &recorder{statuses: []int{http.StatusBadRequest}}
+		server := httptest.NewServer(r.handler())
+		defer server.Close()
+
+		webhook := config.Webhook{Name: "on-call", URL: server.URL, Retries: 3}
+		err := newTestNotifier(webhook).Notify(context.Background(), []Change{testChange})
+		assert.EqualError(t, err, "webhook on-call: unexpected response 400 Bad Request")
+		assert.Len(t, r.bodies, 1)
+	})
+}
+
+func TestNotifyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	webhook := config.Webhook{Name: "slow", URL: server.URL, Timeout: "50ms"}
+	err := newTestNotifier(webhook).Notify(context.Background(), []Change{testChange})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,144 @@
+// Package pattern implements the glob and regular expression matching used to select condition types.
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IsPattern reports whether s should be interpreted as a pattern rather than a literal condition type.
+// Regular expressions are wrapped in slashes (e.g. /^team-a\..*$/), anything containing glob
+// metacharacters (*, ? or [) is treated as a glob.
+func IsPattern(s string) bool {
+	return isRegex(s) || strings.ContainsAny(s, "*?[")
+}
+
+// Validate checks that the pattern is syntactically valid.
+// It returns an error describing the problem if it is not.
+func Validate(pattern string) error {
+	if isRegex(pattern) {
+		if _, err := regexp.Compile(regexBody(pattern)); err != nil {
+			return fmt.Errorf("invalid regular expression %s: %w", pattern, err)
+		}
+		return nil
+	}
+
+	if _, err := compileGlob(pattern); err != nil {
+		return fmt.Errorf("invalid glob %s: %w", pattern, err)
+	}
+
+	return nil
+}
+
+// Match reports whether s matches the pattern.
+// Literal patterns only match themselves and regular expressions are matched with regexp.MatchString.
+// In globs * matches any sequence of characters and ? any single character, both including the "/"
+// separating the prefix of a condition type, so *Pressure matches example.com/DiskPressure.
+// [...] matches a character class, negated with [!...] or [^...], and \ escapes the next character.
+func Match(pattern, s string) (bool, error) {
+	if isRegex(pattern) {
+		return regexp.MatchString(regexBody(pattern), s)
+	}
+
+	re, err := compileGlob(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(s), nil
+}
+
+// errBadGlob is returned for globs with an unterminated character class or a trailing backslash.
+var errBadGlob = errors.New("syntax error in pattern")
+
+// compileGlob translates the glob into an anchored regular expression.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	runes := []rune(pattern)
+
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, errBadGlob
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end, class, err := globClass(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(class)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, errBadGlob
+	}
+
+	return re, nil
+}
+
+// globClass translates the character class starting at index start, just after its "[".
+// It returns the index of the closing "]" and the class as a regular expression.
+// Escaped characters are written as hexadecimal escapes, so an escaped "-" never forms a range.
+func globClass(runes []rune, start int) (int, string, error) {
+	var b strings.Builder
+	b.WriteString("[")
+
+	i := start
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		b.WriteString("^")
+		i++
+	}
+
+	empty := true
+	for ; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case ']':
+			if empty {
+				return 0, "", errBadGlob
+			}
+			b.WriteString("]")
+			return i, b.String(), nil
+		case '-':
+			b.WriteRune(c)
+		case '\\':
+			i++
+			if i == len(runes) {
+				return 0, "", errBadGlob
+			}
+			fmt.Fprintf(&b, `\x{%x}`, runes[i])
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		empty = false
+	}
+
+	return 0, "", errBadGlob
+}
+
+// isRegex reports whether the pattern is a regular expression wrapped in slashes.
+func isRegex(pattern string) bool {
+	return len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// regexBody strips the surrounding slashes from a regular expression pattern.
+func regexBody(pattern string) string {
+	return pattern[1 : len(pattern)-1]
+}
@@ -0,0 +1,80 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPattern(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Ready", false},
+		{"team-a.example.com/Healthy", false},
+		{"team-a.example.com/*", true},
+		{"Disk?", true},
+		{"[A-Z]*", true},
+		{"/^team-a/", true},
+		{"/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(tt.want, IsPattern(tt.in))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name    string
+		pattern string
+		in      string
+		want    bool
+	}{
+		{"literal match", "Ready", "Ready", true},
+		{"literal mismatch", "Ready", "NotReady", false},
+		{"glob prefix", "team-a.example.com/*", "team-a.example.com/Healthy", true},
+		{"glob other prefix", "team-a.example.com/*", "team-b.example.com/Healthy", false},
+		{"glob suffix", "*Pressure", "DiskPressure", true},
+		{"glob suffix prefixed type", "*Pressure", "team-a.example.com/DiskPressure", true},
+		{"glob infix prefixed type", "*Legacy*", "team-a.example.com/LegacyProbe", true},
+		{"glob star prefixed type", "*", "team-a.example.com/Healthy", true},
+		{"glob question mark matches slash", "example.com?Healthy", "example.com/Healthy", true},
+		{"glob class", "[DM]*Pressure", "MemoryPressure", true},
+		{"glob negated class", "[!DM]*Pressure", "MemoryPressure", false},
+		{"glob escaped star", `Disk\*`, "Disk*", true},
+		{"glob escaped star mismatch", `Disk\*`, "DiskPressure", false},
+		{"glob dots are literal", "team-a.example.com/*", "team-aXexample.com/Healthy", false},
+		{"glob is anchored", "Disk*", "team-a.example.com/DiskPressure", false},
+		{"regex", "/^(Disk|Memory)Pressure$/", "MemoryPressure", true},
+		{"regex mismatch", "/^(Disk|Memory)Pressure$/", "PIDPressure", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Match(tt.pattern, tt.in)
+			assert.NoError(err)
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(Validate("team-a.example.com/*"))
+	assert.NoError(Validate("/^team-a/"))
+	assert.NoError(Validate("[A-Z]*"))
+	assert.Error(Validate("[Ready"))
+	assert.Error(Validate("[]Ready"))
+	assert.Error(Validate(`Ready\`))
+	assert.Error(Validate("[z-a]*"))
+	assert.Error(Validate("/(Ready/"))
+}